@@ -1,13 +1,44 @@
 package belaykit
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 // Agent is the interface that all coding agent backends implement.
 type Agent interface {
 	Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error)
 }
 
-// Result holds the response from an agent invocation.
+// Result holds the response from an agent invocation along with a summary
+// of the run. Fields other than Text are best-effort: they are filled in
+// from whatever the underlying CLI reports.
 type Result struct {
-	Text string
+	Text string `json:"text"`
+
+	RunID     string `json:"run_id,omitempty"`     // Unique ID of this Run call, as stamped on its events
+	SessionID string `json:"session_id,omitempty"` // Agent session ID (from the system init event)
+	Model     string `json:"model,omitempty"`      // Resolved model, as reported by the CLI when available
+	Subtype   string `json:"subtype,omitempty"`    // Terminal result subtype, e.g. "success" or "error_max_turns"
+	IsError   bool   `json:"is_error"`             // Whether the run ended with an error result
+
+	CostUSD    float64 `json:"cost_usd"`    // Total cost in USD
+	DurationMS int64   `json:"duration_ms"` // Total duration in milliseconds
+	NumTurns   int     `json:"num_turns"`   // Number of agentic turns
+
+	InputTokens         int `json:"input_tokens"`          // Input tokens billed for the run
+	OutputTokens        int `json:"output_tokens"`         // Output tokens generated by the run
+	CacheReadTokens     int `json:"cache_read_tokens"`     // Input tokens served from the prompt cache
+	CacheCreationTokens int `json:"cache_creation_tokens"` // Input tokens written to the prompt cache
+
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"` // Tool invocations in the order they were made
+}
+
+// ToolCall summarizes a single tool invocation within a run.
+type ToolCall struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Output     string          `json:"output"`
+	DurationMS int64           `json:"duration_ms"` // Time between tool_use and tool_result; 0 if no result arrived
 }
@@ -0,0 +1,158 @@
+package belaykit
+
+import (
+	"slices"
+	"sync"
+)
+
+// OverflowPolicy decides what an AsyncEventHandler does with an event that
+// arrives while its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the caller wait for room in the buffer. No event
+	// is lost, but a slow handler eventually stalls the run again.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest discards the oldest buffered event to make room.
+	OverflowDropOldest
+
+	// OverflowDropNewest discards the incoming event.
+	OverflowDropNewest
+)
+
+// essential reports whether e must survive a full buffer. The drop policies
+// never discard a session's system init or result event, which consumers
+// such as chat threads are built around: such an event evicts the oldest
+// other buffered event instead, and waits for room only when every
+// buffered event is essential.
+func essential(e Event) bool {
+	switch e.Type {
+	case EventSystem:
+		return e.Subtype == "init"
+	case EventResult, EventResultError:
+		return true
+	}
+	return false
+}
+
+// AsyncEventHandler delivers events to a handler on its own goroutine, so a
+// slow handler (a network sink, say) does not hold up the CLI's output. Events
+// are delivered one at a time, in order. Create one with AsyncHandler and
+// pass its Handle method as the run's handler.
+//
+// The delivery goroutine runs only while events are pending, so a handler
+// that is never closed does not leak it.
+type AsyncEventHandler struct {
+	handler EventHandler
+	size    int
+	policy  OverflowPolicy
+
+	mu      sync.Mutex
+	cond    *sync.Cond // signalled whenever queue, running or closed change
+	queue   []Event
+	running bool // a worker is delivering the queue
+	closed  bool
+	dropped int64
+}
+
+// AsyncHandler wraps h so that events are buffered and delivered on a
+// separate goroutine. Up to bufferSize events wait for delivery; policy
+// decides what happens to events beyond that, except that the drop
+// policies never discard the system init and result events. A bufferSize
+// below 1 is treated as 1.
+//
+// Call Close when the handler is no longer needed, to deliver the remaining
+// events and refuse later ones.
+func AsyncHandler(h EventHandler, bufferSize int, policy OverflowPolicy) *AsyncEventHandler {
+	a := &AsyncEventHandler{
+		handler: h,
+		size:    max(bufferSize, 1),
+		policy:  policy,
+	}
+	a.cond = sync.NewCond(&a.mu)
+	return a
+}
+
+// Handle queues e for delivery. It is an EventHandler. Events handled after
+// Close are dropped.
+func (a *AsyncEventHandler) Handle(e Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for !a.closed && len(a.queue) >= a.size {
+		if a.policy == OverflowBlock {
+			a.cond.Wait()
+			continue
+		}
+		if a.policy == OverflowDropNewest && !essential(e) {
+			a.dropped++
+			return
+		}
+		i := slices.IndexFunc(a.queue, func(q Event) bool { return !essential(q) })
+		if i < 0 {
+			a.cond.Wait()
+			continue
+		}
+		a.queue = slices.Delete(a.queue, i, i+1)
+		a.dropped++
+	}
+	if a.closed {
+		a.dropped++
+		return
+	}
+	a.queue = append(a.queue, e)
+	if !a.running {
+		a.running = true
+		go a.run()
+	}
+	a.cond.Broadcast()
+}
+
+// Dropped returns the number of events discarded so far because the buffer
+// was full or the handler was closed.
+func (a *AsyncEventHandler) Dropped() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
+
+// Flush waits until every event queued so far has been delivered.
+func (a *AsyncEventHandler) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.running {
+		a.cond.Wait()
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// delivered. Calling Close more than once has no further effect.
+func (a *AsyncEventHandler) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	a.cond.Broadcast()
+	for a.running {
+		a.cond.Wait()
+	}
+}
+
+// run delivers queued events until the queue is empty, then exits; Handle
+// starts a new worker for the next event.
+func (a *AsyncEventHandler) run() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for len(a.queue) > 0 {
+		e := a.queue[0]
+		a.queue[0] = Event{}
+		a.queue = a.queue[1:]
+		a.cond.Broadcast()
+
+		a.mu.Unlock()
+		a.handler(e)
+		a.mu.Lock()
+	}
+	a.running = false
+	a.cond.Broadcast()
+}
@@ -0,0 +1,155 @@
+package belaykit
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestAsyncHandlerDeliversInOrder(t *testing.T) {
+	var got []string
+	a := AsyncHandler(func(e Event) { got = append(got, e.Text) }, 2, OverflowBlock)
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		a.Handle(Event{Type: EventAssistant, Text: s})
+	}
+	a.Flush()
+	if len(got) != 5 || got[0] != "a" || got[4] != "e" {
+		t.Errorf("delivered %v, want a..e in order", got)
+	}
+	if a.Dropped() != 0 {
+		t.Errorf("Dropped = %d, want 0 with OverflowBlock", a.Dropped())
+	}
+	a.Close()
+}
+
+func TestAsyncHandlerOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		want   []string
+	}{
+		{"drop newest", OverflowDropNewest, []string{"first", "1", "2"}},
+		{"drop oldest", OverflowDropOldest, []string{"first", "3", "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var mu sync.Mutex
+			var got []string
+			a := AsyncHandler(func(e Event) {
+				if e.Text == "first" {
+					close(started)
+					<-release
+				}
+				mu.Lock()
+				got = append(got, e.Text)
+				mu.Unlock()
+			}, 2, tt.policy)
+
+			// The handler is busy with "first"; the rest overflow a buffer of 2.
+			a.Handle(Event{Text: "first"})
+			<-started
+			for _, s := range []string{"1", "2", "3", "4"} {
+				a.Handle(Event{Text: s})
+			}
+			close(release)
+			a.Close()
+
+			if a.Dropped() != 2 {
+				t.Errorf("Dropped = %d, want 2", a.Dropped())
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(got) != len(tt.want) {
+				t.Fatalf("delivered %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("delivered %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAsyncHandlerKeepsSessionEvents(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest} {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var got []EventType
+		a := AsyncHandler(func(e Event) {
+			if e.Text == "first" {
+				close(started)
+				<-release
+			}
+			got = append(got, e.Type)
+		}, 2, policy)
+
+		a.Handle(Event{Type: EventAssistant, Text: "first"})
+		<-started
+		a.Handle(Event{Type: EventSystem, Subtype: "init"})
+		a.Handle(Event{Type: EventToolUse})
+		a.Handle(Event{Type: EventToolUse})
+		a.Handle(Event{Type: EventResult})
+		close(release)
+		a.Close()
+
+		want := []EventType{EventAssistant, EventSystem, EventResult}
+		if !slices.Equal(got, want) {
+			t.Errorf("policy %d delivered %v, want %v", policy, got, want)
+		}
+	}
+}
+
+func TestAsyncHandlerBlockWaitsForRoom(t *testing.T) {
+	release := make(chan struct{})
+	a := AsyncHandler(func(e Event) { <-release }, 1, OverflowBlock)
+	a.Handle(Event{Text: "busy"})
+	a.Handle(Event{Text: "queued"})
+
+	handled := make(chan struct{})
+	go func() {
+		a.Handle(Event{Text: "blocked"})
+		close(handled)
+	}()
+	select {
+	case <-handled:
+		t.Fatal("Handle returned while the buffer was full")
+	default:
+	}
+	close(release)
+	<-handled
+	a.Close()
+}
+
+func TestAsyncHandlerClose(t *testing.T) {
+	var n int
+	a := AsyncHandler(func(Event) { n++ }, 8, OverflowBlock)
+	a.Handle(Event{})
+	a.Handle(Event{})
+	a.Close()
+	a.Close()
+	if n != 2 {
+		t.Errorf("delivered %d events before Close returned, want 2", n)
+	}
+
+	a.Handle(Event{})
+	if n != 2 || a.Dropped() != 1 {
+		t.Errorf("after Close: delivered %d, dropped %d; want 2 and 1", n, a.Dropped())
+	}
+}
+
+func TestAsyncHandlerRestartsAfterIdle(t *testing.T) {
+	var n int
+	a := AsyncHandler(func(Event) { n++ }, 8, OverflowBlock)
+	a.Handle(Event{})
+	a.Flush()
+	// The worker has exited; the next event must start a new one.
+	a.Handle(Event{})
+	a.Flush()
+	if n != 2 {
+		t.Errorf("delivered %d events, want 2", n)
+	}
+	a.Close()
+}
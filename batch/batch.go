@@ -0,0 +1,269 @@
+// Package batch runs JSONL files of jobs through a belaykit.Agent and writes
+// one JSONL result per job. Output is appended as jobs finish, so a crashed
+// or interrupted batch can be resumed: jobs that already succeeded in the
+// output file are skipped.
+//
+// Input lines look like:
+//
+//	{"id": "review-42", "prompt": "Review PR 42", "model": "sonnet", "allowed_tools": ["Read"], "metadata": {"pr": 42}}
+//
+// Usage:
+//
+//	client := claude.NewClient()
+//	sum, err := batch.RunFile(ctx, client, "jobs.jsonl", "results.jsonl", batch.WithConcurrency(8))
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"belaykit"
+)
+
+// Job is one line of a batch input file.
+type Job struct {
+	ID              string         `json:"id,omitempty"` // Defaults to the 1-based line number
+	Prompt          string         `json:"prompt"`
+	Model           string         `json:"model,omitempty"`
+	SystemPrompt    string         `json:"system_prompt,omitempty"`
+	AllowedTools    []string       `json:"allowed_tools,omitempty"`
+	DisallowedTools []string       `json:"disallowed_tools,omitempty"`
+	Metadata        map[string]any `json:"metadata,omitempty"`
+}
+
+// runOptions converts the job's settings to run options.
+func (j Job) runOptions() []belaykit.RunOption {
+	var opts []belaykit.RunOption
+	if j.Model != "" {
+		opts = append(opts, belaykit.WithModel(j.Model))
+	}
+	if j.SystemPrompt != "" {
+		opts = append(opts, belaykit.WithSystemPrompt(j.SystemPrompt))
+	}
+	if len(j.AllowedTools) > 0 {
+		opts = append(opts, belaykit.WithAllowedTools(j.AllowedTools...))
+	}
+	if len(j.DisallowedTools) > 0 {
+		opts = append(opts, belaykit.WithDisallowedTools(j.DisallowedTools...))
+	}
+	return opts
+}
+
+// Output is one line of a batch output file.
+type Output struct {
+	ID       string          `json:"id"`
+	Result   belaykit.Result `json:"result"`
+	CostUSD  float64         `json:"cost_usd"`
+	Error    string          `json:"error,omitempty"`
+	Metadata map[string]any  `json:"metadata,omitempty"`
+}
+
+// Summary counts what a batch did.
+type Summary struct {
+	Total     int // Jobs in the input
+	Skipped   int // Jobs already completed in a previous run
+	Succeeded int
+	Failed    int
+	CostUSD   float64 // Cost of the jobs run this time
+}
+
+// Option configures a batch run.
+type Option func(*config)
+
+type config struct {
+	runAll []belaykit.RunAllOption
+}
+
+// WithConcurrency sets how many jobs run at once. Defaults to
+// belaykit.DefaultConcurrency.
+func WithConcurrency(n int) Option {
+	return func(cfg *config) {
+		cfg.runAll = append(cfg.runAll, belaykit.RunAllConcurrency(n))
+	}
+}
+
+// WithRunOptions sets options applied to every job, before the job's own
+// model, system prompt and tools.
+func WithRunOptions(opts ...belaykit.RunOption) Option {
+	return func(cfg *config) {
+		cfg.runAll = append(cfg.runAll, belaykit.RunAllRunOptions(opts...))
+	}
+}
+
+// WithFailFast stops the batch on the first failed job.
+func WithFailFast() Option {
+	return func(cfg *config) {
+		cfg.runAll = append(cfg.runAll, belaykit.RunAllFailFast())
+	}
+}
+
+// ReadJobs parses a JSONL job file. Blank lines are ignored; every other
+// line must have a prompt.
+func ReadJobs(r io.Reader) ([]Job, error) {
+	var jobs []Job
+	seen := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(line, &job); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if strings.TrimSpace(job.Prompt) == "" {
+			return nil, fmt.Errorf("line %d: job has no prompt", n)
+		}
+		if job.ID == "" {
+			job.ID = strconv.Itoa(n)
+		}
+		if prev, ok := seen[job.ID]; ok {
+			return nil, fmt.Errorf("line %d: duplicate job id %q (first on line %d)", n, job.ID, prev)
+		}
+		seen[job.ID] = n
+		jobs = append(jobs, job)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Completed returns the IDs of jobs that succeeded in an existing output
+// file. Lines that do not parse, such as one cut short by a crash, are
+// ignored, as are failed jobs, which run again on resume.
+func Completed(r io.Reader) (map[string]bool, error) {
+	done := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var out Output
+		if err := json.Unmarshal(scanner.Bytes(), &out); err != nil || out.ID == "" {
+			continue
+		}
+		done[out.ID] = out.Error == "" && !out.Result.IsError
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading output: %w", err)
+	}
+	for id, ok := range done {
+		if !ok {
+			delete(done, id)
+		}
+	}
+	return done, nil
+}
+
+// Run runs jobs on agent, skipping IDs in done, and writes an Output line to
+// w as each job finishes. Jobs the batch never started (because it stopped
+// early) are not written.
+func Run(ctx context.Context, agent belaykit.Agent, jobs []Job, done map[string]bool, w io.Writer, opts ...Option) (Summary, error) {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	sum := Summary{Total: len(jobs)}
+	var pending []Job
+	for _, job := range jobs {
+		if done[job.ID] {
+			sum.Skipped++
+			continue
+		}
+		pending = append(pending, job)
+	}
+
+	runJobs := make([]belaykit.Job, len(pending))
+	for i, job := range pending {
+		runJobs[i] = belaykit.Job{ID: job.ID, Prompt: job.Prompt, Opts: job.runOptions()}
+	}
+
+	enc := json.NewEncoder(w)
+	var writeErr error
+	onDone := func(i int, r belaykit.JobResult) {
+		out := Output{
+			ID:       r.ID,
+			Result:   r.Result,
+			CostUSD:  r.Result.CostUSD,
+			Metadata: pending[i].Metadata,
+		}
+		if r.Err != nil {
+			out.Error = r.Err.Error()
+		}
+		sum.CostUSD += r.Result.CostUSD
+		if r.Err != nil || r.Result.IsError {
+			sum.Failed++
+		} else {
+			sum.Succeeded++
+		}
+		if err := enc.Encode(out); err != nil && writeErr == nil {
+			writeErr = fmt.Errorf("writing result for job %s: %w", r.ID, err)
+		}
+	}
+
+	runAll := append(cfg.runAll, belaykit.RunAllOnDone(onDone))
+	_, err := belaykit.RunAll(ctx, agent, runJobs, runAll...)
+	return sum, errors.Join(err, writeErr)
+}
+
+// RunFile runs the jobs in the JSONL file at inPath and appends results to
+// outPath, creating it if needed. Jobs that already succeeded in outPath are
+// skipped, so rerunning after a crash picks up where the batch left off.
+func RunFile(ctx context.Context, agent belaykit.Agent, inPath, outPath string, opts ...Option) (Summary, error) {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return Summary{}, fmt.Errorf("opening jobs: %w", err)
+	}
+	defer in.Close()
+	jobs, err := ReadJobs(in)
+	if err != nil {
+		return Summary{}, fmt.Errorf("%s: %w", inPath, err)
+	}
+
+	out, err := os.OpenFile(outPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return Summary{}, fmt.Errorf("opening output: %w", err)
+	}
+	defer out.Close()
+	done, err := Completed(out)
+	if err != nil {
+		return Summary{}, fmt.Errorf("%s: %w", outPath, err)
+	}
+	if err := terminateLastLine(out); err != nil {
+		return Summary{}, fmt.Errorf("%s: %w", outPath, err)
+	}
+
+	sum, err := Run(ctx, agent, jobs, done, out, opts...)
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("closing output: %w", closeErr)
+	}
+	return sum, err
+}
+
+// terminateLastLine adds a newline to f if its last line was cut short, so
+// new results start on a line of their own.
+func terminateLastLine(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	_, err = f.Write([]byte("\n"))
+	return err
+}
@@ -0,0 +1,154 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"belaykit"
+)
+
+// echoAgent answers every prompt with its model, failing prompts that start
+// with "fail".
+type echoAgent struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (a *echoAgent) Run(ctx context.Context, prompt string, opts ...belaykit.RunOption) (belaykit.Result, error) {
+	cfg := belaykit.NewRunConfig(opts...)
+	a.mu.Lock()
+	a.prompts = append(a.prompts, prompt)
+	a.mu.Unlock()
+	if strings.HasPrefix(prompt, "fail") {
+		return belaykit.Result{}, errors.New("boom")
+	}
+	return belaykit.Result{
+		Text:    prompt + " via " + cfg.Model + " " + strings.Join(cfg.AllowedTools, ","),
+		CostUSD: 0.01,
+	}, nil
+}
+
+func TestReadJobs(t *testing.T) {
+	input := `{"id":"a","prompt":"one","model":"opus","metadata":{"k":"v"}}
+
+{"prompt":"two","allowed_tools":["Read","Grep"]}
+`
+	jobs, err := ReadJobs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadJobs error: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("jobs = %d, want 2", len(jobs))
+	}
+	if jobs[0].ID != "a" || jobs[0].Model != "opus" || jobs[0].Metadata["k"] != "v" {
+		t.Errorf("jobs[0] = %+v", jobs[0])
+	}
+	if jobs[1].ID != "3" || len(jobs[1].AllowedTools) != 2 {
+		t.Errorf("jobs[1] = %+v, want line-number ID", jobs[1])
+	}
+
+	_, err = ReadJobs(strings.NewReader(`{"id":"a","prompt":"x"}` + "\n" + `{"id":"a","prompt":"y"}`))
+	if err == nil || !strings.Contains(err.Error(), "line 2: duplicate job id") {
+		t.Errorf("err = %v, want duplicate ID error", err)
+	}
+	_, err = ReadJobs(strings.NewReader(`{"prompt":`))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+		t.Errorf("err = %v, want line-qualified parse error", err)
+	}
+}
+
+func TestReadJobsRequiresPrompt(t *testing.T) {
+	// A file in some other JSONL shape must not run as empty prompts.
+	input := `{"id":"a","prompt":"one"}
+
+{"request_id":"user-001","title":"Add X","body":"Please add X."}
+`
+	_, err := ReadJobs(strings.NewReader(input))
+	if err == nil || err.Error() != "line 3: job has no prompt" {
+		t.Errorf("err = %v, want missing prompt error on line 3", err)
+	}
+	_, err = ReadJobs(strings.NewReader(`{"id":"a","prompt":"  "}`))
+	if err == nil || !strings.Contains(err.Error(), "line 1: job has no prompt") {
+		t.Errorf("err = %v, want missing prompt error for a blank prompt", err)
+	}
+}
+
+func TestRunFileResumes(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "jobs.jsonl")
+	outPath := filepath.Join(dir, "results.jsonl")
+	jobs := `{"id":"a","prompt":"one","model":"opus","metadata":{"pr":1}}
+{"id":"b","prompt":"fail two"}
+{"id":"c","prompt":"three","allowed_tools":["Read"]}
+`
+	if err := os.WriteFile(inPath, []byte(jobs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A previous run finished "a" and crashed halfway through writing "c".
+	prev := `{"id":"a","result":{"text":"done before"},"cost_usd":0.5}
+{"id":"c","resu`
+	if err := os.WriteFile(outPath, []byte(prev), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := &echoAgent{}
+	sum, err := RunFile(t.Context(), agent, inPath, outPath, WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("RunFile error: %v", err)
+	}
+	want := Summary{Total: 3, Skipped: 1, Succeeded: 1, Failed: 1, CostUSD: 0.01}
+	if sum != want {
+		t.Errorf("summary = %+v, want %+v", sum, want)
+	}
+	if len(agent.prompts) != 2 {
+		t.Errorf("prompts = %q, want a skipped", agent.prompts)
+	}
+
+	outputs := readOutputs(t, outPath)
+	if len(outputs) != 3 {
+		t.Fatalf("outputs = %d, want previous line plus 2 new", len(outputs))
+	}
+	if got := outputs["b"]; got.Error != "boom" {
+		t.Errorf("b = %+v, want error recorded", got)
+	}
+	if got := outputs["c"]; got.Result.Text != "three via  Read" || got.CostUSD != 0.01 {
+		t.Errorf("c = %+v", got)
+	}
+
+	// Resuming again only retries the failed job.
+	agent = &echoAgent{}
+	sum, err = RunFile(t.Context(), agent, inPath, outPath)
+	if err != nil {
+		t.Fatalf("RunFile error: %v", err)
+	}
+	if sum.Skipped != 2 || len(agent.prompts) != 1 || agent.prompts[0] != "fail two" {
+		t.Errorf("summary = %+v, prompts = %q, want only b retried", sum, agent.prompts)
+	}
+}
+
+func readOutputs(t *testing.T, path string) map[string]Output {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	outputs := make(map[string]Output)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var out Output
+		if err := json.Unmarshal(scanner.Bytes(), &out); err != nil {
+			continue
+		}
+		outputs[out.ID] = out
+	}
+	return outputs
+}
@@ -0,0 +1,377 @@
+package belaykittest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"belaykit"
+)
+
+// Behavior is what a conformance scenario needs the agent under test to do.
+type Behavior int
+
+const (
+	// Reply starts session Scenario.SessionID, streams Scenario.Text as
+	// assistant text and finishes successfully with Scenario.Text as the
+	// result.
+	Reply Behavior = iota
+
+	// Hang starts session Scenario.SessionID and then never finishes on its
+	// own; the run only ends when it is interrupted.
+	Hang
+
+	// Fail starts session Scenario.SessionID and then fails in a way the
+	// provider reports as transient, such as a rate-limit exit or an error
+	// result.
+	Fail
+)
+
+// String returns the behavior's name.
+func (b Behavior) String() string {
+	switch b {
+	case Reply:
+		return "reply"
+	case Hang:
+		return "hang"
+	case Fail:
+		return "fail"
+	}
+	return "unknown"
+}
+
+// Scenario describes the agent a Factory must build.
+type Scenario struct {
+	Behavior  Behavior
+	SessionID string
+	Text      string
+
+	// Observability must be attached to the agent, so that its completion
+	// records reach the suite.
+	Observability belaykit.ObservabilityProvider
+}
+
+// Factory builds the agent under test for one scenario. Factories for
+// CLI-backed agents typically point the client at a fake executable that
+// prints a canned stream.
+type Factory func(t *testing.T, s Scenario) belaykit.Agent
+
+// conformanceTimeout bounds how long any single conformance run may take.
+const conformanceTimeout = 10 * time.Second
+
+const conformancePrompt = "conformance prompt"
+
+// RunConformance checks that the agents built by factory behave like the
+// built-in clients:
+//
+//   - events are ordered: system init, then assistant_start, then assistant
+//     text, and a run ends with exactly one result or result_error event;
+//   - events carry the run's ID and count up in sequence from 1;
+//   - no events are emitted after Run returns;
+//   - assistant text is written to the run's OutputStream;
+//   - the run's TraceID and JobID reach the CompletionRecord;
+//   - cancelling the context interrupts the run, which fails with an error
+//     wrapping belaykit.ErrInterrupted and the cancellation cause;
+//   - transient failures are reported as retryable errors.
+//
+// Each check runs as a subtest of t.
+func RunConformance(t *testing.T, factory Factory) {
+	t.Run("EventOrder", func(t *testing.T) {
+		s := Scenario{Behavior: Reply, SessionID: "conformance-order", Text: "ordered reply"}
+		var rec eventRecorder
+		res, err := runScenario(t, factory, s, belaykit.WithEventHandler(rec.handle))
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		events := rec.events()
+		checkOrder(t, events)
+		checkTerminal(t, events, true)
+		checkStamps(t, events, res.RunID)
+		rec.checkQuiet(t, len(events))
+
+		if res.SessionID != s.SessionID {
+			t.Errorf("Result.SessionID = %q, want %q", res.SessionID, s.SessionID)
+		}
+		if res.Text != s.Text {
+			t.Errorf("Result.Text = %q, want %q", res.Text, s.Text)
+		}
+		if res.IsError {
+			t.Error("Result.IsError is true for a successful run")
+		}
+	})
+
+	t.Run("OutputStream", func(t *testing.T) {
+		s := Scenario{Behavior: Reply, SessionID: "conformance-stream", Text: "streamed reply"}
+		var rec eventRecorder
+		var out syncBuilder
+		if _, err := runScenario(t, factory, s, belaykit.WithEventHandler(rec.handle), belaykit.WithOutputStream(&out)); err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		var want strings.Builder
+		for _, e := range rec.events() {
+			if e.Type == belaykit.EventAssistant {
+				want.WriteString(e.Text)
+			}
+		}
+		if want.Len() == 0 {
+			t.Fatal("no assistant text was emitted")
+		}
+		if got := out.String(); got != want.String() {
+			t.Errorf("OutputStream got %q, want the assistant text %q", got, want.String())
+		}
+	})
+
+	t.Run("CompletionRecord", func(t *testing.T) {
+		provider := &recordingProvider{}
+		s := Scenario{Behavior: Reply, SessionID: "conformance-trace", Text: "traced reply", Observability: provider}
+		res, err := runScenario(t, factory, s, belaykit.WithTraceID("trace-conformance"), belaykit.WithJobID("job-conformance"))
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		records := provider.completions()
+		if len(records) != 1 {
+			t.Fatalf("got %d completion records, want 1", len(records))
+		}
+		r := records[0]
+		if r.TraceID != "trace-conformance" {
+			t.Errorf("TraceID = %q, want trace-conformance", r.TraceID)
+		}
+		if r.JobID != "job-conformance" {
+			t.Errorf("JobID = %q, want job-conformance", r.JobID)
+		}
+		if r.SessionID != s.SessionID {
+			t.Errorf("SessionID = %q, want %q", r.SessionID, s.SessionID)
+		}
+		if r.Prompt != conformancePrompt {
+			t.Errorf("Prompt = %q, want %q", r.Prompt, conformancePrompt)
+		}
+		if r.Response != res.Text {
+			t.Errorf("Response = %q, want the result text %q", r.Response, res.Text)
+		}
+		if r.Provider == "" {
+			t.Error("Provider is empty")
+		}
+	})
+
+	t.Run("Cancellation", func(t *testing.T) {
+		s := Scenario{Behavior: Hang, SessionID: "conformance-cancel"}
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		var rec eventRecorder
+		handler := func(e belaykit.Event) {
+			rec.handle(e)
+			if e.Type == belaykit.EventSystem {
+				cancel()
+			}
+		}
+		res, err := runScenarioContext(t, ctx, factory, s, belaykit.WithEventHandler(handler), belaykit.WithGracePeriod(time.Second))
+		if !errors.Is(err, belaykit.ErrInterrupted) {
+			t.Fatalf("err = %v, want ErrInterrupted", err)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want to wrap context.Canceled", err)
+		}
+		if belaykit.IsRetryable(res, err) {
+			t.Error("a cancelled run is reported as retryable")
+		}
+		if res.SessionID != s.SessionID {
+			t.Errorf("partial Result.SessionID = %q, want %q", res.SessionID, s.SessionID)
+		}
+		events := rec.events()
+		checkTerminal(t, events, false)
+		rec.checkQuiet(t, len(events))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		s := Scenario{Behavior: Fail, SessionID: "conformance-fail"}
+		var rec eventRecorder
+		res, err := runScenario(t, factory, s, belaykit.WithEventHandler(rec.handle))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if errors.Is(err, belaykit.ErrInterrupted) {
+			t.Errorf("err = %v, a failed run must not report an interruption", err)
+		}
+		if !belaykit.IsRetryable(res, err) {
+			t.Errorf("err = %v is not retryable, want a transient failure", err)
+		}
+		events := rec.events()
+		checkTerminal(t, events, false)
+		rec.checkQuiet(t, len(events))
+	})
+}
+
+func runScenario(t *testing.T, factory Factory, s Scenario, opts ...belaykit.RunOption) (belaykit.Result, error) {
+	return runScenarioContext(t, t.Context(), factory, s, opts...)
+}
+
+// runScenarioContext runs the scenario's agent and fails the test if Run
+// does not return within conformanceTimeout.
+func runScenarioContext(t *testing.T, ctx context.Context, factory Factory, s Scenario, opts ...belaykit.RunOption) (belaykit.Result, error) {
+	t.Helper()
+	agent := factory(t, s)
+
+	type outcome struct {
+		res belaykit.Result
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		res, err := agent.Run(ctx, conformancePrompt, opts...)
+		done <- outcome{res, err}
+	}()
+	select {
+	case o := <-done:
+		return o.res, o.err
+	case <-time.After(conformanceTimeout):
+		t.Fatalf("%s run did not return within %s", s.Behavior, conformanceTimeout)
+		return belaykit.Result{}, nil
+	}
+}
+
+// checkOrder verifies that the first system init precedes the first
+// assistant_start, which precedes the first assistant text.
+func checkOrder(t *testing.T, events []belaykit.Event) {
+	t.Helper()
+	first := func(match func(belaykit.Event) bool) int {
+		for i, e := range events {
+			if match(e) {
+				return i
+			}
+		}
+		return -1
+	}
+	init := first(func(e belaykit.Event) bool { return e.Type == belaykit.EventSystem && e.Subtype == "init" })
+	start := first(func(e belaykit.Event) bool { return e.Type == belaykit.EventAssistantStart })
+	text := first(func(e belaykit.Event) bool { return e.Type == belaykit.EventAssistant })
+	switch {
+	case init < 0:
+		t.Errorf("no system init event in %v", eventTypes(events))
+	case start < 0:
+		t.Errorf("no assistant_start event in %v", eventTypes(events))
+	case text < 0:
+		t.Errorf("no assistant event in %v", eventTypes(events))
+	case !(init < start && start < text):
+		t.Errorf("events %v: want system init, then assistant_start, then assistant", eventTypes(events))
+	}
+}
+
+// checkTerminal verifies that there is at most one result or result_error
+// event, and that it is the last event. When required, there must be one.
+func checkTerminal(t *testing.T, events []belaykit.Event, required bool) {
+	t.Helper()
+	n, last := 0, -1
+	for i, e := range events {
+		if e.Type == belaykit.EventResult || e.Type == belaykit.EventResultError {
+			n++
+			last = i
+		}
+	}
+	switch {
+	case n == 0 && required:
+		t.Errorf("no result event in %v", eventTypes(events))
+	case n > 1:
+		t.Errorf("%d terminal events in %v, want one", n, eventTypes(events))
+	case n == 1 && last != len(events)-1:
+		t.Errorf("events %v continue after the terminal event", eventTypes(events))
+	}
+}
+
+// checkStamps verifies that every event carries the run's ID and that
+// sequence numbers count up from 1.
+func checkStamps(t *testing.T, events []belaykit.Event, runID string) {
+	t.Helper()
+	if runID == "" {
+		t.Error("Result.RunID is empty")
+	}
+	for i, e := range events {
+		if e.RunID != runID {
+			t.Errorf("%s event has RunID %q, want %q", e.Type, e.RunID, runID)
+		}
+		if e.Seq != int64(i+1) {
+			t.Errorf("%s event has Seq %d, want %d", e.Type, e.Seq, i+1)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("%s event has no Time", e.Type)
+		}
+	}
+}
+
+func eventTypes(events []belaykit.Event) []belaykit.EventType {
+	types := make([]belaykit.EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+// eventRecorder collects the events of a run.
+type eventRecorder struct {
+	mu   sync.Mutex
+	list []belaykit.Event
+}
+
+func (r *eventRecorder) handle(e belaykit.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.list = append(r.list, e)
+}
+
+func (r *eventRecorder) events() []belaykit.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]belaykit.Event(nil), r.list...)
+}
+
+// checkQuiet fails the test if events arrive after Run returned with n.
+func (r *eventRecorder) checkQuiet(t *testing.T, n int) {
+	t.Helper()
+	time.Sleep(50 * time.Millisecond)
+	if got := len(r.events()); got != n {
+		t.Errorf("%d events were emitted after Run returned", got-n)
+	}
+}
+
+// syncBuilder is a strings.Builder safe for concurrent writes.
+type syncBuilder struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuilder) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuilder) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+// recordingProvider is an ObservabilityProvider that keeps completion
+// records.
+type recordingProvider struct {
+	mu      sync.Mutex
+	records []belaykit.CompletionRecord
+}
+
+func (p *recordingProvider) StartSession(map[string]any) string                     { return "" }
+func (p *recordingProvider) StartTrace(belaykit.TraceConfig, map[string]any) string { return "" }
+func (p *recordingProvider) EndTrace(string, map[string]any)                        {}
+
+func (p *recordingProvider) RecordCompletion(r belaykit.CompletionRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = append(p.records, r)
+}
+
+func (p *recordingProvider) completions() []belaykit.CompletionRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]belaykit.CompletionRecord(nil), p.records...)
+}
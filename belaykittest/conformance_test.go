@@ -0,0 +1,29 @@
+package belaykittest
+
+import (
+	"errors"
+	"testing"
+
+	"belaykit"
+)
+
+func TestFakeAgentConformance(t *testing.T) {
+	RunConformance(t, func(t *testing.T, s Scenario) belaykit.Agent {
+		var call Call
+		switch s.Behavior {
+		case Reply:
+			call.Events = []belaykit.Event{InitEvent(s.SessionID, "fake-model"), TextEvent(s.Text)}
+			call.Result = belaykit.Result{Text: s.Text, SessionID: s.SessionID}
+		case Hang:
+			call.Events = []belaykit.Event{InitEvent(s.SessionID, "fake-model")}
+			call.Block = true
+		case Fail:
+			call.Events = []belaykit.Event{
+				InitEvent(s.SessionID, "fake-model"),
+				ResultEvent(belaykit.Result{Text: "overloaded", IsError: true}),
+			}
+			call.Err = errors.New("overloaded")
+		}
+		return &FakeAgent{Calls: []Call{call}, Observability: s.Observability}
+	})
+}
@@ -0,0 +1,250 @@
+// Package belaykittest provides test doubles for code built on belaykit.
+//
+// FakeAgent replays scripted calls without running a CLI, so orchestration,
+// event handlers and observability providers can be unit-tested:
+//
+//	fake := &belaykittest.FakeAgent{Calls: []belaykittest.Call{{
+//	    Events: []belaykit.Event{
+//	        belaykittest.InitEvent("sess-1", "sonnet"),
+//	        belaykittest.TextEvent("Looking..."),
+//	    },
+//	    Result: belaykit.Result{Text: "done", CostUSD: 0.01},
+//	}}}
+//	res, err := fake.Run(ctx, "review this")
+package belaykittest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"belaykit"
+)
+
+// Verify FakeAgent implements belaykit.Agent.
+var _ belaykit.Agent = (*FakeAgent)(nil)
+
+// Call scripts one Run call of a FakeAgent.
+type Call struct {
+	// Events are emitted in order, through the same supervision as the real
+	// clients (see belaykit.BeginRun). As with the claude client, an
+	// assistant_start event follows the system init event and each batch of
+	// tool results. Unless Err is set or Events already end the run, a
+	// result event built from Result is emitted last.
+	Events []belaykit.Event
+
+	// Result is returned from Run, with RunID filled in if unset. When it
+	// is the zero value, the Result accumulated from Events is returned
+	// instead.
+	Result belaykit.Result
+
+	// Err is returned from Run.
+	Err error
+
+	// Block makes Run wait, after emitting Events, until its context is
+	// done. Run then returns the partial Result and an error wrapping
+	// belaykit.ErrInterrupted, as the real clients do.
+	Block bool
+}
+
+// Request records the arguments of one Run call.
+type Request struct {
+	Prompt string
+	Config belaykit.RunConfig
+}
+
+// FakeAgent is a belaykit.Agent whose calls are scripted. The zero value is
+// ready to use; set Calls before the first Run. A FakeAgent is safe for
+// concurrent use; concurrent calls take scripted calls in arrival order.
+type FakeAgent struct {
+	// Calls scripts each Run call in turn. Calls beyond the script fail.
+	Calls []Call
+
+	// Model is reported when a run does not set one with WithModel.
+	Model string
+
+	// ProviderName is returned by Name and recorded as the completion's
+	// provider. Defaults to "fake".
+	ProviderName string
+
+	// EventHandler is the default handler, like a client's
+	// WithDefaultEventHandler.
+	EventHandler belaykit.EventHandler
+
+	// Observability receives a CompletionRecord for every run that reaches
+	// a result event, like a client's WithObservability.
+	Observability belaykit.ObservabilityProvider
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// Name returns ProviderName, or "fake".
+func (f *FakeAgent) Name() string {
+	if f.ProviderName != "" {
+		return f.ProviderName
+	}
+	return "fake"
+}
+
+// DefaultEventHandler returns EventHandler.
+func (f *FakeAgent) DefaultEventHandler() belaykit.EventHandler {
+	return f.EventHandler
+}
+
+// Requests returns the prompt and resolved configuration of every call so
+// far, in order.
+func (f *FakeAgent) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+// Run plays the next scripted call.
+func (f *FakeAgent) Run(ctx context.Context, prompt string, opts ...belaykit.RunOption) (belaykit.Result, error) {
+	cfg := belaykit.NewRunConfig(opts...)
+
+	f.mu.Lock()
+	n := len(f.requests)
+	f.requests = append(f.requests, Request{Prompt: prompt, Config: cfg})
+	f.mu.Unlock()
+	if n >= len(f.Calls) {
+		return belaykit.Result{}, fmt.Errorf("belaykittest: unexpected call %d to FakeAgent scripted with %d calls", n+1, len(f.Calls))
+	}
+	call := f.Calls[n]
+
+	if err := cfg.CheckBudget(); err != nil {
+		return belaykit.Result{}, err
+	}
+
+	model := f.Model
+	if cfg.Model != "" {
+		model = cfg.Model
+	}
+	handler := cfg.ResolveEventHandler(f.EventHandler)
+	ctx, tracker := belaykit.BeginRun(ctx, cfg, model, handler)
+	defer tracker.End()
+
+	var ended bool
+	emit := func(e belaykit.Event) {
+		tracker.Emit(e)
+		if e.Type == belaykit.EventAssistant && cfg.OutputStream != nil {
+			cfg.OutputStream.Write([]byte(e.Text))
+		}
+		if e.Type == belaykit.EventResult || e.Type == belaykit.EventResultError {
+			ended = true
+		}
+	}
+
+	for i, e := range call.Events {
+		if ctx.Err() != nil {
+			break
+		}
+		emit(e)
+		if startsTurn(e, call.Events[i+1:]) {
+			emit(belaykit.Event{Type: belaykit.EventAssistantStart})
+		}
+	}
+	if call.Block {
+		<-ctx.Done()
+	}
+	if ctx.Err() != nil {
+		return tracker.Result(), tracker.InterruptError()
+	}
+	if !ended && call.Err == nil {
+		emit(ResultEvent(call.Result))
+	}
+
+	res := call.Result
+	if reflect.ValueOf(res).IsZero() {
+		res = tracker.Result()
+	} else if res.RunID == "" {
+		res.RunID = tracker.Result().RunID
+	}
+	if ended && f.Observability != nil {
+		f.Observability.RecordCompletion(belaykit.CompletionRecord{
+			TraceID:      cfg.TraceID,
+			JobID:        cfg.JobID,
+			RunID:        res.RunID,
+			ParentRunID:  cfg.ParentRunID,
+			SessionID:    res.SessionID,
+			Provider:     f.Name(),
+			Prompt:       prompt,
+			Response:     res.Text,
+			Model:        res.Model,
+			CostUSD:      res.CostUSD,
+			DurationMS:   res.DurationMS,
+			NumTurns:     res.NumTurns,
+			IsError:      res.IsError,
+			InputTokens:  res.InputTokens,
+			OutputTokens: res.OutputTokens,
+		})
+	}
+	return res, call.Err
+}
+
+// startsTurn reports whether the claude client would emit assistant_start
+// after e: following the init event, and following a batch of tool results.
+func startsTurn(e belaykit.Event, rest []belaykit.Event) bool {
+	switch e.Type {
+	case belaykit.EventSystem:
+		return e.Subtype == "init"
+	case belaykit.EventToolResult:
+		return len(rest) == 0 || rest[0].Type != belaykit.EventToolResult
+	}
+	return false
+}
+
+// InitEvent returns the system init event a CLI sends when a session starts.
+func InitEvent(sessionID, model string) belaykit.Event {
+	return belaykit.Event{Type: belaykit.EventSystem, Subtype: "init", SessionID: sessionID, Model: model}
+}
+
+// TextEvent returns an assistant text event.
+func TextEvent(text string) belaykit.Event {
+	return belaykit.Event{Type: belaykit.EventAssistant, Text: text}
+}
+
+// ToolEvents returns a tool_use event and its matching tool_result. input is
+// marshalled to JSON.
+func ToolEvents(id, name string, input any, output string) []belaykit.Event {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		panic(fmt.Sprintf("belaykittest: marshalling tool input: %v", err))
+	}
+	return []belaykit.Event{
+		{Type: belaykit.EventToolUse, ToolID: id, ToolName: name, ToolInput: raw},
+		{Type: belaykit.EventToolResult, ToolID: id, Text: output},
+	}
+}
+
+// ResultEvent returns the terminal event for res: EventResultError when
+// res.IsError, otherwise EventResult.
+func ResultEvent(res belaykit.Result) belaykit.Event {
+	typ := belaykit.EventResult
+	subtype := res.Subtype
+	if res.IsError {
+		typ = belaykit.EventResultError
+	}
+	if subtype == "" {
+		subtype = "success"
+		if res.IsError {
+			subtype = "error"
+		}
+	}
+	return belaykit.Event{
+		Type:                typ,
+		Subtype:             subtype,
+		Text:                res.Text,
+		IsError:             res.IsError,
+		CostUSD:             res.CostUSD,
+		Duration:            res.DurationMS,
+		NumTurns:            res.NumTurns,
+		InputTokens:         res.InputTokens,
+		OutputTokens:        res.OutputTokens,
+		CacheReadTokens:     res.CacheReadTokens,
+		CacheCreationTokens: res.CacheCreationTokens,
+	}
+}
@@ -0,0 +1,150 @@
+package belaykittest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"belaykit"
+)
+
+func TestFakeAgentEmitsScriptedEvents(t *testing.T) {
+	provider := &recordingProvider{}
+	fake := &FakeAgent{
+		Model:         "sonnet",
+		Observability: provider,
+		Calls: []Call{{
+			Events: append([]belaykit.Event{
+				InitEvent("sess-1", "sonnet-4"),
+				TextEvent("Reading. "),
+			}, ToolEvents("t1", "Read", map[string]string{"path": "a.go"}, "package a")...),
+			Result: belaykit.Result{Text: "done", SessionID: "sess-1", Model: "sonnet-4", CostUSD: 0.02, NumTurns: 2},
+		}},
+	}
+
+	var events []belaykit.Event
+	var out strings.Builder
+	res, err := fake.Run(t.Context(), "review",
+		belaykit.WithEventHandler(func(e belaykit.Event) { events = append(events, e) }),
+		belaykit.WithOutputStream(&out),
+		belaykit.WithTraceID("trace-1"),
+		belaykit.WithJobID("job-1"),
+	)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if res.Text != "done" || res.CostUSD != 0.02 {
+		t.Errorf("Result = %+v", res)
+	}
+
+	var types []belaykit.EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+		if e.JobID != "job-1" {
+			t.Errorf("event %s has JobID %q, want job-1", e.Type, e.JobID)
+		}
+	}
+	want := []belaykit.EventType{
+		belaykit.EventSystem, belaykit.EventAssistantStart, belaykit.EventAssistant,
+		belaykit.EventToolUse, belaykit.EventToolResult, belaykit.EventAssistantStart, belaykit.EventResult,
+	}
+	if len(types) != len(want) {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("event types = %v, want %v", types, want)
+		}
+	}
+	if string(events[3].ToolInput) != `{"path":"a.go"}` {
+		t.Errorf("ToolInput = %s", events[3].ToolInput)
+	}
+	if out.String() != "Reading. " {
+		t.Errorf("output stream = %q", out.String())
+	}
+
+	records := provider.completions()
+	if len(records) != 1 {
+		t.Fatalf("got %d completion records, want 1", len(records))
+	}
+	r := records[0]
+	if r.TraceID != "trace-1" || r.JobID != "job-1" || r.SessionID != "sess-1" || r.Provider != "fake" ||
+		r.Prompt != "review" || r.Response != "done" || r.Model != "sonnet-4" || r.CostUSD != 0.02 {
+		t.Errorf("completion record = %+v", r)
+	}
+}
+
+func TestFakeAgentResultFromEvents(t *testing.T) {
+	fake := &FakeAgent{Model: "opus", Calls: []Call{{
+		Events: []belaykit.Event{
+			InitEvent("sess-2", ""),
+			ResultEvent(belaykit.Result{Text: "42", CostUSD: 0.01}),
+		},
+	}}}
+	res, err := fake.Run(t.Context(), "answer")
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if res.Text != "42" || res.SessionID != "sess-2" || res.Model != "opus" || res.CostUSD != 0.01 {
+		t.Errorf("Result = %+v", res)
+	}
+}
+
+func TestFakeAgentError(t *testing.T) {
+	boom := errors.New("boom")
+	provider := &recordingProvider{}
+	fake := &FakeAgent{
+		Observability: provider,
+		Calls:         []Call{{Events: []belaykit.Event{TextEvent("partial")}, Err: boom}},
+	}
+	var last belaykit.Event
+	_, err := fake.Run(t.Context(), "fail", belaykit.WithEventHandler(func(e belaykit.Event) { last = e }))
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	if last.Type != belaykit.EventAssistant {
+		t.Errorf("last event = %s, want no result event after an error", last.Type)
+	}
+	if records := provider.completions(); len(records) != 0 {
+		t.Errorf("got %d completion records for a run without a result event", len(records))
+	}
+}
+
+func TestFakeAgentBlockUntilCancelled(t *testing.T) {
+	fake := &FakeAgent{Calls: []Call{{Events: []belaykit.Event{InitEvent("sess-3", "")}, Block: true}}}
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	res, err := fake.Run(ctx, "wait")
+	if !errors.Is(err, belaykit.ErrInterrupted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want interrupted deadline", err)
+	}
+	if res.SessionID != "sess-3" {
+		t.Errorf("partial Result = %+v", res)
+	}
+}
+
+func TestFakeAgentBlockHonoursRunLimits(t *testing.T) {
+	fake := &FakeAgent{Calls: []Call{{Block: true}}}
+	_, err := fake.Run(t.Context(), "stall", belaykit.WithIdleTimeout(20*time.Millisecond))
+	if !errors.Is(err, belaykit.ErrStalled) {
+		t.Fatalf("err = %v, want ErrStalled", err)
+	}
+}
+
+func TestFakeAgentRecordsRequestsAndRunsOut(t *testing.T) {
+	fake := &FakeAgent{Calls: []Call{{Result: belaykit.Result{Text: "one"}}}}
+	if _, err := fake.Run(t.Context(), "first", belaykit.WithModel("haiku")); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if _, err := fake.Run(t.Context(), "second"); err == nil {
+		t.Fatal("expected error for unscripted call")
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 2 || reqs[0].Prompt != "first" || reqs[0].Config.Model != "haiku" || reqs[1].Prompt != "second" {
+		t.Errorf("Requests = %+v", reqs)
+	}
+}
@@ -0,0 +1,110 @@
+package belaykit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBudgetExceeded indicates a run was stopped because it exceeded its
+// cost limit. Errors returned for such runs match it with errors.Is and
+// carry a *BudgetExceededError with the details.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetExceededError reports the limit that stopped a run.
+type BudgetExceededError struct {
+	Scope    string  // BudgetScopeRun or BudgetScopeShared
+	LimitUSD float64 // The limit that was exceeded
+	SpentUSD float64 // Spend (actual or estimated) when the limit was hit
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s budget exceeded: spent $%.4f of $%.4f", e.Scope, e.SpentUSD, e.LimitUSD)
+}
+
+// Is reports whether target is ErrBudgetExceeded.
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// Budget scopes, reported in BudgetExceededError.Scope and on EventBudget
+// events in Event.Subtype.
+const (
+	BudgetScopeRun    = "run"    // Per-run limit set with WithMaxCostUSD
+	BudgetScopeShared = "shared" // Shared Budget set with WithBudget
+)
+
+// Budget is a cost limit shared by many runs, for example every run in a
+// trace or session. Runs report their live cost to the budget as they go;
+// when the total reaches the limit, the run that reached it is interrupted
+// and later runs are refused. A run whose final reported cost reaches the
+// limit has already finished, so it is charged but not interrupted.
+//
+// A Budget is safe for concurrent use.
+type Budget struct {
+	mu       sync.Mutex
+	limit    float64
+	spent    float64                 // cost of finished runs
+	inflight map[*RunTracker]float64 // live cost of runs in progress
+}
+
+// NewBudget creates a budget with the given limit in USD.
+func NewBudget(limitUSD float64) *Budget {
+	return &Budget{limit: limitUSD, inflight: make(map[*RunTracker]float64)}
+}
+
+// Limit returns the budget limit in USD.
+func (b *Budget) Limit() float64 {
+	return b.limit
+}
+
+// Spent returns the cost of finished runs plus the live cost of runs in
+// progress.
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total()
+}
+
+// Remaining returns the headroom left in USD. It is negative once the
+// budget has been overrun.
+func (b *Budget) Remaining() float64 {
+	return b.limit - b.Spent()
+}
+
+// Exceeded reports whether spending has reached the limit.
+func (b *Budget) Exceeded() bool {
+	return b.Spent() >= b.limit
+}
+
+// Err returns a *BudgetExceededError if the budget is exhausted, or nil.
+func (b *Budget) Err() error {
+	if spent := b.Spent(); spent >= b.limit {
+		return &BudgetExceededError{Scope: BudgetScopeShared, LimitUSD: b.limit, SpentUSD: spent}
+	}
+	return nil
+}
+
+func (b *Budget) total() float64 {
+	total := b.spent
+	for _, cost := range b.inflight {
+		total += cost
+	}
+	return total
+}
+
+// update records the live cost of an in-progress run and returns the total.
+func (b *Budget) update(run *RunTracker, cost float64) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inflight[run] = cost
+	return b.total()
+}
+
+// commit moves a finished run's cost from in-flight to spent.
+func (b *Budget) commit(run *RunTracker, cost float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.inflight, run)
+	b.spent += cost
+}
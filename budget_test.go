@@ -0,0 +1,117 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBudgetAccounting(t *testing.T) {
+	b := NewBudget(1.0)
+	run1, run2 := NewRunTracker(""), NewRunTracker("")
+
+	b.update(run1, 0.25)
+	b.update(run2, 0.5)
+	if got := b.Spent(); got != 0.75 {
+		t.Errorf("Spent = %v, want 0.75", got)
+	}
+	b.commit(run1, 0.3)
+	if got := b.Spent(); got != 0.8 {
+		t.Errorf("Spent = %v, want 0.8", got)
+	}
+	if b.Exceeded() || b.Err() != nil {
+		t.Error("budget should not be exceeded yet")
+	}
+
+	b.commit(run2, 0.7)
+	if !b.Exceeded() {
+		t.Error("budget should be exceeded")
+	}
+	var berr *BudgetExceededError
+	if err := b.Err(); !errors.As(err, &berr) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Err = %v, want *BudgetExceededError", err)
+	}
+	if berr.Scope != BudgetScopeShared || berr.LimitUSD != 1.0 {
+		t.Errorf("error = %+v", berr)
+	}
+}
+
+func TestRunTrackerMaxCostAborts(t *testing.T) {
+	var events []Event
+	cfg := NewRunConfig(WithMaxCostUSD(0.5), WithModelPricing(ModelPricing{OutputPerMTok: 1_000_000}))
+	ctx, tr := BeginRun(t.Context(), cfg, "", func(e Event) { events = append(events, e) })
+	defer tr.End()
+
+	tr.Emit(Event{Type: EventAssistant, Text: "ab"}) // 1 token at $1/token
+	if ctx.Err() == nil {
+		t.Fatal("expected run context to be cancelled")
+	}
+	if !tr.Aborted() {
+		t.Error("expected Aborted to be true")
+	}
+
+	err := tr.InterruptError()
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("InterruptError = %v, want interrupted budget error", err)
+	}
+	if len(events) != 2 || events[0].Type != EventAssistant || events[1].Type != EventBudget {
+		t.Fatalf("events = %+v, want assistant then budget", events)
+	}
+	if b := events[1]; b.Subtype != BudgetScopeRun || !b.IsError || b.BudgetSpentUSD != 1 || b.BudgetLimitUSD != 0.5 {
+		t.Errorf("budget event = %+v", b)
+	}
+}
+
+func TestRunTrackerSharedBudget(t *testing.T) {
+	b := NewBudget(1.0)
+	cfg := NewRunConfig(WithBudget(b))
+
+	var events []Event
+	ctx, tr := BeginRun(t.Context(), cfg, "", func(e Event) { events = append(events, e) })
+	tr.Emit(Event{Type: EventResult, Text: "done", CostUSD: 0.4})
+	tr.End()
+
+	if context.Cause(ctx) != context.Canceled {
+		t.Errorf("cause = %v, want plain cancellation after End", context.Cause(ctx))
+	}
+	if len(events) != 2 || events[0].Type != EventBudget || events[1].Type != EventResult {
+		t.Fatalf("events = %+v, want budget before result", events)
+	}
+	if e := events[0]; e.Subtype != BudgetScopeShared || e.BudgetSpentUSD != 0.4 || e.IsError {
+		t.Errorf("budget event = %+v", e)
+	}
+	if got := b.Spent(); got != 0.4 {
+		t.Errorf("Spent = %v, want 0.4 after End", got)
+	}
+
+	// The final cost overruns the budget, but the run has already finished.
+	events = nil
+	_, tr2 := BeginRun(t.Context(), cfg, "", func(e Event) { events = append(events, e) })
+	tr2.Emit(Event{Type: EventResult, CostUSD: 0.7})
+	tr2.End()
+	if tr2.Aborted() {
+		t.Error("a finished run was aborted for its final cost")
+	}
+	if len(events) != 2 || !events[0].IsError {
+		t.Errorf("events = %+v, want an overrun budget event before the result", events)
+	}
+	if err := cfg.CheckBudget(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("CheckBudget = %v, want ErrBudgetExceeded", err)
+	}
+}
+
+func TestBudgetLimitReached(t *testing.T) {
+	// Spending exactly the limit exhausts it, both for the run and for the
+	// next run.
+	b := NewBudget(1.0)
+	cfg := NewRunConfig(WithBudget(b), WithMaxCostUSD(1.0), WithModelPricing(ModelPricing{OutputPerMTok: 1_000_000}))
+	_, tr := BeginRun(t.Context(), cfg, "", nil)
+	tr.Emit(Event{Type: EventAssistant, Text: "ab"}) // 1 token at $1/token
+	tr.End()
+	if !tr.Aborted() {
+		t.Error("run that reached its limit was not aborted")
+	}
+	if !b.Exceeded() || cfg.CheckBudget() == nil {
+		t.Error("budget spent to its limit is not exhausted")
+	}
+}
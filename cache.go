@@ -0,0 +1,238 @@
+package belaykit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Verify CachingAgent implements Agent.
+var _ Agent = (*CachingAgent)(nil)
+
+// CacheMode controls how a single run uses a CachingAgent.
+type CacheMode int
+
+const (
+	// CacheDefault reads cached results and stores new ones.
+	CacheDefault CacheMode = iota
+	// CacheBypass skips the cache entirely: the run is neither looked up
+	// nor stored.
+	CacheBypass
+	// CacheRefresh skips the lookup but stores the new result, replacing
+	// any cached one.
+	CacheRefresh
+)
+
+// CacheOption configures a CachingAgent.
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	ttl      time.Duration
+	readOnly bool
+	salt     string
+}
+
+// CacheTTL expires cached results older than d. Zero, the default, keeps
+// them forever.
+func CacheTTL(d time.Duration) CacheOption {
+	return func(cfg *cacheConfig) { cfg.ttl = d }
+}
+
+// CacheReadOnly serves cached results but never writes new ones, for CI
+// runs against a checked-in cache.
+func CacheReadOnly() CacheOption {
+	return func(cfg *cacheConfig) { cfg.readOnly = true }
+}
+
+// CacheSalt mixes salt into every cache key. Change it to invalidate the
+// cache when something outside the prompt and options changes, such as the
+// repository the agent works in.
+func CacheSalt(salt string) CacheOption {
+	return func(cfg *cacheConfig) { cfg.salt = salt }
+}
+
+// CachingAgent serves repeated runs from a directory of cached results.
+// A run is keyed by a hash of its prompt, model, system prompt, turn limit,
+// tool lists, tool policy, guardrails, working and additional directories,
+// resumed session, output schema and the cache salt. Only successful runs
+// are cached.
+//
+// On a hit the recorded events are replayed to the run's event handler and
+// hooks (the wrapped client's default handler is not called), assistant
+// text is written to the run's OutputStream, and the cached Result is
+// returned without running the agent.
+type CachingAgent struct {
+	agent Agent
+	dir   string
+	cfg   cacheConfig
+	now   func() time.Time // for testing
+}
+
+// Cache returns an Agent that caches agent's results in dir.
+func Cache(agent Agent, dir string, opts ...CacheOption) *CachingAgent {
+	c := &CachingAgent{agent: agent, dir: dir, now: time.Now}
+	for _, opt := range opts {
+		opt(&c.cfg)
+	}
+	return c
+}
+
+// cacheEntry is the on-disk form of a cached run.
+type cacheEntry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Prompt    string    `json:"prompt"`
+	Result    Result    `json:"result"`
+	Events    []Event   `json:"events"`
+}
+
+// EnforcesOutputSchema reports whether the wrapped agent does (see
+// SchemaEnforcer).
+func (c *CachingAgent) EnforcesOutputSchema() bool {
+	return enforcesOutputSchema(c.agent)
+}
+
+// DefaultEventHandler returns the wrapped agent's default event handler
+// (see the DefaultEventHandler function).
+func (c *CachingAgent) DefaultEventHandler() EventHandler {
+	return DefaultEventHandler(c.agent)
+}
+
+// Run returns the cached result for the run if there is a fresh one, and
+// otherwise runs the wrapped agent and caches its result. If the result
+// cannot be cached, it is returned along with the write error.
+func (c *CachingAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	cfg := NewRunConfig(opts...)
+	if cfg.CacheMode == CacheBypass {
+		return c.agent.Run(ctx, prompt, opts...)
+	}
+
+	path := filepath.Join(c.dir, c.key(prompt, cfg)+".json")
+	if cfg.CacheMode != CacheRefresh {
+		if entry, ok := c.load(path); ok {
+			res := entry.Result
+			res.RunID = c.replay(cfg, entry)
+			return res, nil
+		}
+	}
+
+	var events []Event
+	record := func(e Event) {
+		// Replays are tagged with the replaying run's job and stamped as a
+		// run of their own.
+		e.JobID, e.RunID, e.ParentRunID, e.Seq, e.Time = "", "", "", 0, time.Time{}
+		events = append(events, e)
+	}
+	res, err := c.agent.Run(ctx, prompt, append(slices.Clip(opts), WithEventHook(record))...)
+	if err != nil || res.IsError || c.cfg.readOnly {
+		return res, err
+	}
+
+	entry := cacheEntry{CreatedAt: c.now(), Prompt: prompt, Result: res, Events: events}
+	if err := c.store(path, entry); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// guardKey identifies a GuardRule in a cache key. Matchers are functions,
+// so a rule is known by its name, tools and input fields.
+type guardKey struct {
+	Name   string   `json:"name"`
+	Tools  []string `json:"tools"`
+	Fields []string `json:"fields"`
+}
+
+// key hashes everything that determines a run's output.
+func (c *CachingAgent) key(prompt string, cfg RunConfig) string {
+	var schema string
+	if cfg.OutputSchema != nil {
+		schema = cfg.OutputSchema.String()
+	}
+	var guards []guardKey
+	for _, r := range cfg.Guardrails {
+		guards = append(guards, guardKey{Name: r.Name, Tools: r.Tools, Fields: slices.Sorted(maps.Keys(r.Input))})
+	}
+	data, _ := json.Marshal(struct {
+		Prompt          string      `json:"prompt"`
+		Model           string      `json:"model"`
+		SystemPrompt    string      `json:"system_prompt"`
+		MaxTurns        int         `json:"max_turns"`
+		AllowedTools    []string    `json:"allowed_tools"`
+		DisallowedTools []string    `json:"disallowed_tools"`
+		ToolPolicy      *ToolPolicy `json:"tool_policy"`
+		Guardrails      []guardKey  `json:"guardrails"`
+		WorkDir         string      `json:"work_dir"`
+		AdditionalDirs  []string    `json:"additional_dirs"`
+		ResumeSessionID string      `json:"resume_session_id"`
+		OutputSchema    string      `json:"output_schema"`
+		Salt            string      `json:"salt"`
+	}{prompt, cfg.Model, cfg.SystemPrompt, cfg.MaxTurns, cfg.AllowedTools, cfg.DisallowedTools, cfg.ToolPolicy, guards,
+		cfg.WorkDir, cfg.AdditionalDirs, cfg.ResumeSessionID, schema, c.cfg.salt})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// load reads a cache entry, treating missing, unreadable and expired entries
+// as misses.
+func (c *CachingAgent) load(path string) (cacheEntry, bool) {
+	var entry cacheEntry
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &entry) != nil {
+		return cacheEntry{}, false
+	}
+	if c.cfg.ttl > 0 && c.now().Sub(entry.CreatedAt) > c.cfg.ttl {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store writes entry atomically, so concurrent readers never see a partial
+// file.
+func (c *CachingAgent) store(path string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return nil
+}
+
+// replay sends a cached run's events to the run's handler as a new run,
+// and returns the new run's ID.
+func (c *CachingAgent) replay(cfg RunConfig, entry cacheEntry) string {
+	handler := cfg.ResolveEventHandler(nil)
+	stamper := newEventStamper(cfg.ParentRunID, c.now)
+	for _, e := range entry.Events {
+		if handler != nil {
+			handler(stamper.stamp(e))
+		}
+		if e.Type == EventAssistant && cfg.OutputStream != nil {
+			cfg.OutputStream.Write([]byte(e.Text))
+		}
+	}
+	return stamper.runID
+}
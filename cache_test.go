@@ -0,0 +1,148 @@
+package belaykit
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingAgent emits a fixed event stream and counts its calls.
+type countingAgent struct {
+	calls int
+	res   Result
+}
+
+func (a *countingAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	a.calls++
+	cfg := NewRunConfig(opts...)
+	if h := cfg.ResolveEventHandler(nil); h != nil {
+		h(Event{Type: EventSystem, Subtype: "init", SessionID: "sess-1"})
+		h(Event{Type: EventAssistant, Text: "hello"})
+		h(Event{Type: EventResult, Text: a.res.Text, IsError: a.res.IsError})
+	}
+	return a.res, nil
+}
+
+func TestCacheHitReplaysEvents(t *testing.T) {
+	dir := t.TempDir()
+	agent := &countingAgent{res: Result{Text: "answer", CostUSD: 0.5}}
+	c := Cache(agent, dir)
+
+	if _, err := c.Run(t.Context(), "q", WithModel("opus")); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	var events []Event
+	var out strings.Builder
+	res, err := c.Run(t.Context(), "q", WithModel("opus"),
+		WithEventHandler(func(e Event) { events = append(events, e) }),
+		WithOutputStream(&out),
+	)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if agent.calls != 1 {
+		t.Errorf("agent calls = %d, want 1", agent.calls)
+	}
+	if res.Text != "answer" || res.CostUSD != 0.5 {
+		t.Errorf("cached result = %+v", res)
+	}
+	if len(events) != 3 || events[0].SessionID != "sess-1" || events[2].Type != EventResult {
+		t.Errorf("replayed events = %+v", events)
+	}
+	if out.String() != "hello" {
+		t.Errorf("OutputStream = %q, want %q", out.String(), "hello")
+	}
+	// A replay is a run of its own.
+	for i, e := range events {
+		if e.RunID == "" || e.RunID != res.RunID || e.Seq != int64(i+1) || e.Time.IsZero() {
+			t.Errorf("replayed event %d stamped %q/%d/%v, want run %q", i, e.RunID, e.Seq, e.Time, res.RunID)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	agent := &countingAgent{res: Result{Text: "answer"}}
+	c := Cache(agent, t.TempDir())
+
+	runs := [][]RunOption{
+		{WithModel("opus")},
+		{WithModel("opus")},
+		{WithModel("sonnet")},
+		{WithModel("opus"), WithSystemPrompt("be terse")},
+		{WithModel("opus"), WithAllowedTools("Read")},
+		{WithModel("opus"), WithDisallowedTools("Bash")},
+		{WithModel("opus"), WithMaxTurns(3)},
+		{WithModel("opus"), WithToolPolicy(&ToolPolicy{Access: AccessReadOnly})},
+		{WithModel("opus"), WithToolPolicy(&ToolPolicy{Access: AccessWorkspaceWrite})},
+		{WithModel("opus"), WithGuardrails(CommandRule("no-push", "git push"))},
+		{WithModel("opus"), WithWorkDir("/repo/a")},
+		{WithModel("opus"), WithWorkDir("/repo/b")},
+		{WithModel("opus"), WithAdditionalDirs("/shared")},
+	}
+	for _, opts := range runs {
+		if _, err := c.Run(t.Context(), "q", opts...); err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+	}
+	if agent.calls != 12 {
+		t.Errorf("agent calls = %d, want 12 (one hit)", agent.calls)
+	}
+
+	salted := Cache(agent, c.dir, CacheSalt("v2"))
+	if _, err := salted.Run(t.Context(), "q", WithModel("opus")); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if agent.calls != 13 {
+		t.Errorf("salt should change the key: calls = %d", agent.calls)
+	}
+}
+
+func TestCacheModesAndTTL(t *testing.T) {
+	dir := t.TempDir()
+	agent := &countingAgent{res: Result{Text: "answer"}}
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := Cache(agent, dir, CacheTTL(time.Hour))
+	c.now = func() time.Time { return clock }
+
+	run := func(opts ...RunOption) {
+		t.Helper()
+		if _, err := c.Run(t.Context(), "q", opts...); err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+	}
+
+	run()                   // miss, stored
+	run(WithCacheBypass())  // not looked up
+	run(WithCacheRefresh()) // not looked up, stored again
+	run()                   // hit
+	if agent.calls != 3 {
+		t.Errorf("calls = %d, want 3", agent.calls)
+	}
+
+	clock = clock.Add(2 * time.Hour)
+	run() // expired
+	if agent.calls != 4 {
+		t.Errorf("calls = %d, want expired entry to miss", agent.calls)
+	}
+}
+
+func TestCacheReadOnlyAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	agent := &countingAgent{res: Result{Text: "answer"}}
+
+	ro := Cache(agent, dir, CacheReadOnly())
+	ro.Run(t.Context(), "q")
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("read-only cache wrote %d entries", len(entries))
+	}
+
+	agent.res = Result{Text: "boom", IsError: true}
+	c := Cache(agent, dir)
+	c.Run(t.Context(), "q")
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("error results should not be cached")
+	}
+}
@@ -0,0 +1,275 @@
+package claude
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"belaykit"
+)
+
+// ToolRequest is a tool call the CLI asks permission to run.
+type ToolRequest struct {
+	ToolName string
+	ToolID   string
+	Input    json.RawMessage
+}
+
+// Decision answers a ToolRequest. Build one with Allow, AllowWithInput or
+// Deny.
+type Decision struct {
+	Allow   bool
+	Input   json.RawMessage // Replacement input for an allowed call; nil keeps the original
+	Message string          // Why the call was denied; shown to the model
+}
+
+// Allow approves a tool call as requested.
+func Allow() Decision {
+	return Decision{Allow: true}
+}
+
+// AllowWithInput approves a tool call with its input replaced, for example
+// to pin a command's arguments or redirect a file path.
+func AllowWithInput(input json.RawMessage) Decision {
+	return Decision{Allow: true, Input: input}
+}
+
+// Deny rejects a tool call. The message is returned to the model in place
+// of the tool's output.
+func Deny(message string) Decision {
+	return Decision{Message: message}
+}
+
+// ToolApprover decides whether a tool call may run. It is called while the
+// CLI waits, so it may block, for example on a human reviewer, but it must
+// return once ctx is done. Calls for one run are made one at a time.
+type ToolApprover func(ctx context.Context, req ToolRequest) Decision
+
+// The CLI reaches the approver through an MCP tool. Each run serves it over
+// MCP's streamable HTTP transport from a listener on the loopback interface,
+// at a random path so that other local processes cannot reach it, and
+// registers the server with --mcp-config.
+const (
+	approverServer = "belaykit"
+	approverTool   = "approve"
+
+	mcpProtocolVersion = "2025-06-18"
+)
+
+// approvalServer serves the approval MCP tool to the CLI of one run.
+type approvalServer struct {
+	approver ToolApprover
+	emit     belaykit.EventHandler
+	ctx      context.Context
+	cancel   context.CancelFunc
+	srv      *http.Server
+	url      string   // MCP endpoint
+	args     []string // CLI flags that register the server
+
+	mu sync.Mutex // serializes calls to approver
+}
+
+// startApprovalServer starts serving approver to the CLI. Decisions are
+// reported to emit as EventApproval events. Close the server once the CLI
+// has exited.
+func startApprovalServer(ctx context.Context, approver ToolApprover, emit belaykit.EventHandler) (*approvalServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listening for tool approver: %w", err)
+	}
+	path := "/" + rand.Text()
+	url := "http://" + ln.Addr().String() + path
+
+	config, err := json.Marshal(map[string]any{
+		"mcpServers": map[string]any{
+			approverServer: map[string]any{"type": "http", "url": url},
+		},
+	})
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	s := &approvalServer{
+		approver: approver,
+		emit:     emit,
+		url:      url,
+		args: []string{
+			"--permission-prompt-tool", "mcp__" + approverServer + "__" + approverTool,
+			"--mcp-config", string(config),
+		},
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, s.serveHTTP)
+	s.srv = &http.Server{Handler: mux}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+// Close stops the server and waits for pending decisions, so no event is
+// emitted after it returns.
+func (s *approvalServer) Close() {
+	s.cancel()
+	s.srv.Shutdown(context.Background())
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// serveHTTP answers one JSON-RPC message POSTed by the CLI. Requests get a
+// JSON response; notifications are accepted with no body. The server never
+// sends messages of its own, so it offers no event stream.
+func (s *approvalServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: -32700, Message: err.Error()}})
+		return
+	}
+	if req.ID == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	resp.Result, resp.Error = s.handle(req)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *approvalServer) handle(req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		version := params.ProtocolVersion
+		if version == "" {
+			version = mcpProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": approverServer, "version": "1.0.0"},
+		}, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		return map[string]any{"tools": []any{approveToolSpec}}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string `json:"name"`
+			Arguments struct {
+				ToolName  string          `json:"tool_name"`
+				Input     json.RawMessage `json:"input"`
+				ToolUseID string          `json:"tool_use_id"`
+			} `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: -32602, Message: err.Error()}
+		}
+		if params.Name != approverTool {
+			return nil, &rpcError{Code: -32602, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+		}
+		text, err := json.Marshal(s.decide(ToolRequest{
+			ToolName: params.Arguments.ToolName,
+			ToolID:   params.Arguments.ToolUseID,
+			Input:    params.Arguments.Input,
+		}))
+		if err != nil {
+			return nil, &rpcError{Code: -32603, Message: err.Error()}
+		}
+		return map[string]any{
+			"content": []any{map[string]any{"type": "text", "text": string(text)}},
+		}, nil
+	}
+	return nil, &rpcError{Code: -32601, Message: fmt.Sprintf("method %q not found", req.Method)}
+}
+
+var approveToolSpec = map[string]any{
+	"name":        approverTool,
+	"description": "Decides whether a tool call may run.",
+	"inputSchema": map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"tool_name":   map[string]any{"type": "string"},
+			"input":       map[string]any{"type": "object"},
+			"tool_use_id": map[string]any{"type": "string"},
+		},
+		"required": []string{"tool_name", "input"},
+	},
+}
+
+// permissionResult is the answer the CLI expects from a permission prompt
+// tool.
+type permissionResult struct {
+	Behavior     string          `json:"behavior"`
+	UpdatedInput json.RawMessage `json:"updatedInput,omitempty"`
+	Message      string          `json:"message,omitempty"`
+}
+
+// decide asks the approver about req and reports the decision.
+func (s *approvalServer) decide(req ToolRequest) permissionResult {
+	if len(req.Input) == 0 || string(req.Input) == "null" {
+		req.Input = json.RawMessage(`{}`)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.approver(s.ctx, req)
+
+	ev := belaykit.Event{
+		Type:      belaykit.EventApproval,
+		ToolName:  req.ToolName,
+		ToolID:    req.ToolID,
+		ToolInput: req.Input,
+	}
+	var res permissionResult
+	if d.Allow {
+		input := req.Input
+		if d.Input != nil {
+			input = d.Input
+		}
+		ev.Subtype, ev.ToolInput = belaykit.ApprovalAllow, input
+		res = permissionResult{Behavior: "allow", UpdatedInput: input}
+	} else {
+		message := d.Message
+		if message == "" {
+			message = "Denied by tool approver"
+		}
+		ev.Subtype, ev.Text = belaykit.ApprovalDeny, message
+		res = permissionResult{Behavior: "deny", Message: message}
+	}
+	if s.emit != nil {
+		s.emit(ev)
+	}
+	return res
+}
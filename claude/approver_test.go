@@ -0,0 +1,235 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"belaykit"
+)
+
+// mcpClientEnv makes the test binary act as TestRunToolApprover's MCP
+// client: it POSTs each line of stdin to the URL in the variable and prints
+// each response body on its own line.
+const mcpClientEnv = "BELAYKIT_TEST_MCP_URL"
+
+func TestMain(m *testing.M) {
+	if url := os.Getenv(mcpClientEnv); url != "" {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			resp, err := http.Post(url, "application/json", bytes.NewReader(scanner.Bytes()))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if len(bytes.TrimSpace(body)) > 0 {
+				fmt.Println(strings.TrimSpace(string(body)))
+			}
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestApprovalServerProtocol(t *testing.T) {
+	var mu sync.Mutex
+	var events []belaykit.Event
+	emit := func(e belaykit.Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}
+	approver := func(ctx context.Context, req ToolRequest) Decision {
+		switch req.ToolName {
+		case "Bash":
+			return Deny("no shell")
+		case "Write":
+			return AllowWithInput(json.RawMessage(`{"file_path":"/repo/safe.txt"}`))
+		}
+		return Allow()
+	}
+
+	s, err := startApprovalServer(t.Context(), approver, emit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got := strings.Join(s.args[:2], " "); got != "--permission-prompt-tool mcp__belaykit__approve" {
+		t.Errorf("args = %q", got)
+	}
+	var config struct {
+		MCPServers map[string]map[string]string `json:"mcpServers"`
+	}
+	if err := json.Unmarshal([]byte(s.args[3]), &config); err != nil {
+		t.Fatalf("decoding --mcp-config: %v", err)
+	}
+	if srv := config.MCPServers["belaykit"]; srv["type"] != "http" || srv["url"] != s.url || !strings.HasPrefix(s.url, "http://127.0.0.1:") {
+		t.Errorf("mcp config = %v, want an HTTP server on the loopback interface", config)
+	}
+
+	post := func(line string) *http.Response {
+		t.Helper()
+		resp, err := http.Post(s.url, "application/json", strings.NewReader(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	call := func(line string) map[string]any {
+		t.Helper()
+		resp := post(line)
+		var m map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+			t.Fatalf("decoding response to %s: %v", line, err)
+		}
+		return m
+	}
+	decision := func(resp map[string]any) map[string]any {
+		t.Helper()
+		result, _ := resp["result"].(map[string]any)
+		content, _ := result["content"].([]any)
+		if len(content) != 1 {
+			t.Fatalf("response = %v, want one content block", resp)
+		}
+		var d map[string]any
+		json.Unmarshal([]byte(content[0].(map[string]any)["text"].(string)), &d)
+		return d
+	}
+
+	initResp := call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	if v := initResp["result"].(map[string]any)["protocolVersion"]; v != "2025-03-26" {
+		t.Errorf("protocolVersion = %v, want the client's", v)
+	}
+	if resp := post(`{"jsonrpc":"2.0","method":"notifications/initialized"}`); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification status = %d, want 202", resp.StatusCode)
+	}
+
+	list := call(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	tools := list["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "approve" {
+		t.Errorf("tools = %v", tools)
+	}
+
+	d := decision(call(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"approve","arguments":{"tool_name":"Read","input":{"file_path":"a.go"},"tool_use_id":"toolu_1"}}}`))
+	if d["behavior"] != "allow" || d["updatedInput"].(map[string]any)["file_path"] != "a.go" {
+		t.Errorf("allow decision = %v", d)
+	}
+	d = decision(call(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"approve","arguments":{"tool_name":"Write","input":{"file_path":"/etc/x"},"tool_use_id":"toolu_2"}}}`))
+	if d["behavior"] != "allow" || d["updatedInput"].(map[string]any)["file_path"] != "/repo/safe.txt" {
+		t.Errorf("rewrite decision = %v", d)
+	}
+	d = decision(call(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"approve","arguments":{"tool_name":"Bash","input":{"command":"ls"},"tool_use_id":"toolu_3"}}}`))
+	if d["behavior"] != "deny" || d["message"] != "no shell" {
+		t.Errorf("deny decision = %v", d)
+	}
+
+	unknown := call(`{"jsonrpc":"2.0","id":6,"method":"resources/list"}`)
+	if rpcErr, _ := unknown["error"].(map[string]any); rpcErr["code"] != float64(-32601) {
+		t.Errorf("unknown method response = %v", unknown)
+	}
+
+	if resp, err := http.Get(s.url); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET = %v, %v; want 405", resp, err)
+	}
+	if resp, err := http.Post(s.url[:strings.LastIndex(s.url, "/")]+"/guess", "application/json", strings.NewReader(`{}`)); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST to another path = %v, %v; want 404", resp, err)
+	}
+
+	s.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	want := []struct{ subtype, tool, id, input, text string }{
+		{belaykit.ApprovalAllow, "Read", "toolu_1", `{"file_path":"a.go"}`, ""},
+		{belaykit.ApprovalAllow, "Write", "toolu_2", `{"file_path":"/repo/safe.txt"}`, ""},
+		{belaykit.ApprovalDeny, "Bash", "toolu_3", `{"command":"ls"}`, "no shell"},
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Type != belaykit.EventApproval || e.Subtype != w.subtype || e.ToolName != w.tool || e.ToolID != w.id || string(e.ToolInput) != w.input || e.Text != w.text {
+			t.Errorf("event %d = %+v, want %+v", i, e, w)
+		}
+	}
+}
+
+func TestRunToolApprover(t *testing.T) {
+	dir := t.TempDir()
+	responses := filepath.Join(dir, "responses")
+	// The fake CLI connects to the approval server from --mcp-config like
+	// the real one, using this test binary as its MCP client.
+	client, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exe := writeScript(t, "claude-approver.sh", `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --mcp-config) config="$2" ;;
+    --permission-prompt-tool) tool="$2" ;;
+  esac
+  shift
+done
+url=$(printf '%s' "$config" | sed 's/.*"url":"\([^"]*\)".*/\1/')
+echo '{"type":"system","subtype":"init","session_id":"sess-1"}'
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"rm -rf /"}}]}}'
+printf '%s\n' \
+  '{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}' \
+  '{"jsonrpc":"2.0","method":"notifications/initialized"}' \
+  '{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"approve","arguments":{"tool_name":"Bash","input":{"command":"rm -rf /"},"tool_use_id":"toolu_1"}}}' |
+  `+mcpClientEnv+`="$url" "`+client+`" > `+responses+`
+echo "{\"type\":\"result\",\"subtype\":\"success\",\"result\":\"$tool\"}"
+`)
+
+	var requests []ToolRequest
+	approver := func(ctx context.Context, req ToolRequest) Decision {
+		requests = append(requests, req)
+		return Deny("destructive command")
+	}
+	// The tracker serializes approval events with the CLI's, so the handler
+	// needs no lock.
+	var approvals []belaykit.Event
+	handler := func(e belaykit.Event) {
+		if e.Type == belaykit.EventApproval {
+			approvals = append(approvals, e)
+		}
+	}
+
+	c := NewClient(WithExecutable(exe), WithToolApprover(approver))
+	res, err := c.Run(t.Context(), "clean up", belaykit.WithEventHandler(handler))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if res.Text != "mcp__belaykit__approve" {
+		t.Errorf("permission prompt tool = %q", res.Text)
+	}
+
+	if len(requests) != 1 || requests[0].ToolName != "Bash" || requests[0].ToolID != "toolu_1" || string(requests[0].Input) != `{"command":"rm -rf /"}` {
+		t.Errorf("requests = %+v", requests)
+	}
+	out, err := os.ReadFile(responses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `\"behavior\":\"deny\"`) || !strings.Contains(lines[1], "destructive command") {
+		t.Errorf("responses = %s", out)
+	}
+
+	if len(approvals) != 1 || approvals[0].Subtype != belaykit.ApprovalDeny || approvals[0].Text != "destructive command" {
+		t.Errorf("approval events = %+v", approvals)
+	}
+}
@@ -7,17 +7,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 
 	"belaykit"
+	"belaykit/internal/proc"
 )
 
-// Verify Client implements belaykit.Agent.
-var _ belaykit.Agent = (*Client)(nil)
+// Verify Client implements belaykit.Agent and belaykit.Starter.
+var (
+	_ belaykit.Agent   = (*Client)(nil)
+	_ belaykit.Starter = (*Client)(nil)
+)
 
 // ErrCLINotFound indicates the claude CLI binary was not found on PATH.
-var ErrCLINotFound = errors.New("claude CLI not found")
+var ErrCLINotFound = fmt.Errorf("claude %w", belaykit.ErrCLINotFound)
 
 // ExitError wraps a non-zero exit from the claude CLI process.
 type ExitError struct {
@@ -36,12 +43,19 @@ func (e *ExitError) Unwrap() error {
 	return e.Err
 }
 
+// CLIStderr returns the captured stderr, implementing belaykit.CLIError.
+func (e *ExitError) CLIStderr() string {
+	return e.Stderr
+}
+
 // Client wraps the Claude CLI for headless mode invocations.
 type Client struct {
 	executable    string
 	defaultModel  string
 	eventHandler  belaykit.EventHandler
 	observability belaykit.ObservabilityProvider
+	replayPath    string
+	approver      ToolApprover
 }
 
 // NewClient creates a new Claude CLI client.
@@ -55,7 +69,77 @@ func NewClient(opts ...ClientOption) *Client {
 	return c
 }
 
+// Name returns the provider name, "claude".
+func (c *Client) Name() string {
+	return "claude"
+}
+
+// DefaultEventHandler returns the handler set with WithDefaultEventHandler,
+// so wrappers such as belaykit.Retry can send their own events to it.
+func (c *Client) DefaultEventHandler() belaykit.EventHandler {
+	return c.eventHandler
+}
+
+// EnforcesOutputSchema reports that the client delivers
+// belaykit.WithOutputSchema to the CLI as a system prompt, so
+// belaykit.RunTyped leaves it out of the prompt.
+func (c *Client) EnforcesOutputSchema() bool {
+	return true
+}
+
+// Start runs the Claude CLI in the background and returns a handle to the
+// run. Unless the run sets belaykit.WithModelPricing, the handle's live cost
+// is estimated with PricingForModel.
+func (c *Client) Start(ctx context.Context, prompt string, opts ...belaykit.RunOption) *belaykit.RunHandle {
+	cfg := belaykit.NewRunConfig(opts...)
+	if cfg.Pricing == (belaykit.ModelPricing{}) {
+		model := c.defaultModel
+		if cfg.Model != "" {
+			model = cfg.Model
+		}
+		opts = append(slices.Clip(opts), belaykit.WithModelPricing(PricingForModel(model)))
+	}
+	return belaykit.StartRun(ctx, c, prompt, opts...)
+}
+
+// permissionModes maps policy access levels to Claude permission modes.
+var permissionModes = map[belaykit.Access]string{
+	belaykit.AccessReadOnly:       "plan",
+	belaykit.AccessWorkspaceWrite: "acceptEdits",
+	belaykit.AccessFull:           "bypassPermissions",
+}
+
+// networkTools are the built-in tools that reach the network. They are
+// disallowed when a policy turns the network off; Bash commands are not
+// sandboxed, so deny network commands explicitly if needed.
+var networkTools = []string{"WebFetch", "WebSearch"}
+
+// policyArgs translates a validated ToolPolicy into CLI flags.
+func policyArgs(p belaykit.ToolPolicy) []string {
+	args := []string{"--permission-mode", permissionModes[p.Access]}
+	for _, tool := range p.Allow {
+		args = append(args, "--allowedTools", tool)
+	}
+	deny := p.Deny
+	if !p.Network {
+		deny = append(slices.Clip(deny), networkTools...)
+	}
+	for _, tool := range deny {
+		args = append(args, "--disallowedTools", tool)
+	}
+	return args
+}
+
 // Run executes the Claude CLI with the given prompt and returns the result.
+// If the CLI exits with an error, the returned Result still summarizes
+// whatever the run reported before it failed.
+//
+// When ctx is done the CLI is interrupted rather than killed: it receives
+// SIGINT and has the run's grace period (see belaykit.WithGracePeriod) to
+// emit its final events. Run then returns the partial Result and an error
+// wrapping belaykit.ErrInterrupted and the context's cause. Runs that exceed
+// a cost limit (belaykit.WithMaxCostUSD, belaykit.WithBudget) are
+// interrupted the same way, with a *belaykit.BudgetExceededError as cause.
 func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOption) (belaykit.Result, error) {
 	cfg := belaykit.NewRunConfig(opts...)
 
@@ -65,6 +149,18 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 		model = cfg.Model
 	}
 
+	if err := cfg.CheckBudget(); err != nil {
+		return belaykit.Result{}, err
+	}
+	if cfg.Pricing == (belaykit.ModelPricing{}) {
+		cfg.Pricing = PricingForModel(model)
+	}
+	if cfg.ToolPolicy != nil {
+		if err := cfg.ToolPolicy.Validate(); err != nil {
+			return belaykit.Result{}, err
+		}
+	}
+
 	// Build args
 	args := []string{
 		"-p", prompt,
@@ -72,6 +168,10 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 		"--verbose",
 	}
 
+	if cfg.ToolPolicy != nil {
+		args = append(args, policyArgs(*cfg.ToolPolicy)...)
+	}
+
 	for _, tool := range cfg.AllowedTools {
 		args = append(args, "--allowedTools", tool)
 	}
@@ -92,43 +192,86 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 		args = append(args, "--system-prompt", cfg.SystemPrompt)
 	}
 
-	cmd := exec.CommandContext(ctx, c.executable, args...)
-
-	if cfg.MaxOutputTokens > 0 {
-		cmd.Env = append(os.Environ(), fmt.Sprintf("CLAUDE_CODE_MAX_OUTPUT_TOKENS=%d", cfg.MaxOutputTokens))
+	if cfg.OutputSchema != nil {
+		args = append(args, "--append-system-prompt", cfg.OutputSchema.Prompt())
 	}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return belaykit.Result{}, fmt.Errorf("creating stdout pipe: %w", err)
+	if cfg.ResumeSessionID != "" {
+		args = append(args, "--resume", cfg.ResumeSessionID)
 	}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return belaykit.Result{}, fmt.Errorf("creating stderr pipe: %w", err)
+	for _, dir := range cfg.AdditionalDirs {
+		args = append(args, "--add-dir", dir)
 	}
 
-	if err := cmd.Start(); err != nil {
-		if errors.Is(err, exec.ErrNotFound) {
-			return belaykit.Result{}, ErrCLINotFound
+	// Determine event handler (per-run overrides client default)
+	handler := cfg.ResolveEventHandler(c.eventHandler)
+
+	ctx, tracker := belaykit.BeginRun(ctx, cfg, model, handler)
+	defer tracker.End()
+	emit := tracker.Emit
+
+	var stdout, stderr io.Reader
+	var process *proc.Process
+	if c.replayPath != "" {
+		cassette, err := os.Open(c.replayPath)
+		if err != nil {
+			return belaykit.Result{}, fmt.Errorf("opening replay cassette: %w", err)
+		}
+		defer cassette.Close()
+		stdout, stderr = cassette, strings.NewReader("")
+	} else {
+		if c.approver != nil {
+			approvals, err := startApprovalServer(ctx, c.approver, emit)
+			if err != nil {
+				return belaykit.Result{}, err
+			}
+			defer approvals.Close()
+			args = append(args, approvals.args...)
 		}
-		return belaykit.Result{}, &ExitError{Err: err}
-	}
 
-	// Determine event handler (per-run overrides client default)
-	handler := c.eventHandler
-	if cfg.EventHandler != nil {
-		handler = cfg.EventHandler
+		cmd := exec.Command(c.executable, args...)
+		cmd.Dir = cfg.WorkDir
+
+		var extraEnv []string
+		if cfg.MaxOutputTokens > 0 {
+			extraEnv = append(extraEnv, fmt.Sprintf("CLAUDE_CODE_MAX_OUTPUT_TOKENS=%d", cfg.MaxOutputTokens))
+		}
+		cmd.Env = cfg.CommandEnv(extraEnv...)
+
+		var err error
+		stdout, err = cmd.StdoutPipe()
+		if err != nil {
+			return belaykit.Result{}, fmt.Errorf("creating stdout pipe: %w", err)
+		}
+
+		stderr, err = cmd.StderrPipe()
+		if err != nil {
+			return belaykit.Result{}, fmt.Errorf("creating stderr pipe: %w", err)
+		}
+
+		process, err = proc.Start(ctx, cmd, cfg.GracePeriod)
+		if err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return belaykit.Result{}, ErrCLINotFound
+			}
+			return belaykit.Result{}, &ExitError{Err: err}
+		}
 	}
 
 	// Parse streaming output
-	var resultText string
-	var sessionID string
 	scanner := bufio.NewScanner(stdout)
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
 
 	for scanner.Scan() {
-		line := scanner.Bytes()
+		// Copy the line: events keep it as RawJSON after the scanner moves on.
+		line := bytes.Clone(scanner.Bytes())
+		if process == nil && ctx.Err() != nil {
+			break // a replay has no process to interrupt
+		}
+		if cfg.Recorder != nil {
+			cfg.Recorder.Write(append(line[:len(line):len(line)], '\n'))
+		}
 
 		var event belaykit.StreamEvent
 		if err := json.Unmarshal(line, &event); err != nil {
@@ -139,45 +282,37 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 
 		switch event.Type {
 		case "system":
-			if event.SessionID != "" {
-				sessionID = event.SessionID
-			}
-			if handler != nil {
-				handler(belaykit.Event{
-					Type:      belaykit.EventSystem,
-					SessionID: event.SessionID,
-					Subtype:   event.Subtype,
-					RawJSON:   rawLine,
-				})
-				if event.Subtype == "init" {
-					handler(belaykit.Event{Type: belaykit.EventAssistantStart})
-				}
+			emit(belaykit.Event{
+				Type:      belaykit.EventSystem,
+				SessionID: event.SessionID,
+				Subtype:   event.Subtype,
+				Model:     event.Model,
+				RawJSON:   rawLine,
+			})
+			if event.Subtype == "init" {
+				emit(belaykit.Event{Type: belaykit.EventAssistantStart})
 			}
 		case "assistant":
 			if event.Message != nil {
 				for _, block := range event.Message.Content {
 					switch block.Type {
 					case "text":
-						if handler != nil {
-							handler(belaykit.Event{
-								Type:    belaykit.EventAssistant,
-								Text:    block.Text,
-								RawJSON: rawLine,
-							})
-						}
+						emit(belaykit.Event{
+							Type:    belaykit.EventAssistant,
+							Text:    block.Text,
+							RawJSON: rawLine,
+						})
 						if cfg.OutputStream != nil {
 							cfg.OutputStream.Write([]byte(block.Text))
 						}
 					case "tool_use":
-						if handler != nil {
-							handler(belaykit.Event{
-								Type:      belaykit.EventToolUse,
-								ToolName:  block.Name,
-								ToolID:    block.ID,
-								ToolInput: block.Input,
-								RawJSON:   rawLine,
-							})
-						}
+						emit(belaykit.Event{
+							Type:      belaykit.EventToolUse,
+							ToolName:  block.Name,
+							ToolID:    block.ID,
+							ToolInput: block.Input,
+							RawJSON:   rawLine,
+						})
 					}
 				}
 			}
@@ -187,50 +322,59 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 				for _, block := range event.Message.Content {
 					if block.Type == "tool_result" {
 						hadToolResults = true
-						if handler != nil {
-							handler(belaykit.Event{
-								Type:    belaykit.EventToolResult,
-								Text:    block.Content,
-								ToolID:  block.ToolUseID,
-								RawJSON: rawLine,
-							})
-						}
+						emit(belaykit.Event{
+							Type:    belaykit.EventToolResult,
+							Text:    block.Content,
+							ToolID:  block.ToolUseID,
+							RawJSON: rawLine,
+						})
 					}
 				}
-				if hadToolResults && handler != nil {
-					handler(belaykit.Event{Type: belaykit.EventAssistantStart})
+				if hadToolResults {
+					emit(belaykit.Event{Type: belaykit.EventAssistantStart})
 				}
 			}
 		case "result":
-			resultText = event.Result
 			evType := belaykit.EventResult
 			isError := event.IsError || event.Subtype == "error"
 			if isError {
 				evType = belaykit.EventResultError
 			}
-			if handler != nil {
-				handler(belaykit.Event{
-					Type:     evType,
-					Text:     event.Result,
-					Subtype:  event.Subtype,
-					CostUSD:  event.CostUSD,
-					Duration: event.DurationMS,
-					NumTurns: event.NumTurns,
-					IsError:  isError,
-					RawJSON:  rawLine,
-				})
+			ev := belaykit.Event{
+				Type:     evType,
+				Text:     event.Result,
+				Subtype:  event.Subtype,
+				CostUSD:  event.Cost(),
+				Duration: event.DurationMS,
+				NumTurns: event.NumTurns,
+				IsError:  isError,
+				RawJSON:  rawLine,
+			}
+			if u := event.Usage; u != nil {
+				ev.InputTokens = u.InputTokens
+				ev.OutputTokens = u.OutputTokens
+				ev.CacheReadTokens = u.CacheReadInputTokens
+				ev.CacheCreationTokens = u.CacheCreationInputTokens
 			}
+			emit(ev)
 			if c.observability != nil {
+				res := tracker.Result()
 				c.observability.RecordCompletion(belaykit.CompletionRecord{
 					TraceID:      cfg.TraceID,
-					SessionID:    sessionID,
+					JobID:        cfg.JobID,
+					RunID:        res.RunID,
+					ParentRunID:  cfg.ParentRunID,
+					SessionID:    res.SessionID,
+					Provider:     "claude",
 					Prompt:       prompt,
-					Response:     event.Result,
-					Model:        model,
-					CostUSD:      event.CostUSD,
-					DurationMS:   event.DurationMS,
-					NumTurns:     event.NumTurns,
-					IsError:      isError,
+					Response:     res.Text,
+					Model:        res.Model,
+					CostUSD:      res.CostUSD,
+					DurationMS:   res.DurationMS,
+					NumTurns:     res.NumTurns,
+					IsError:      res.IsError,
+					InputTokens:  res.InputTokens,
+					OutputTokens: res.OutputTokens,
 				})
 			}
 		}
@@ -240,15 +384,25 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 	var stderrBuf bytes.Buffer
 	stderrBuf.ReadFrom(stderr)
 
-	if err := cmd.Wait(); err != nil {
+	if process == nil {
 		if ctx.Err() != nil {
-			return belaykit.Result{}, ctx.Err()
+			return tracker.Result(), tracker.InterruptError()
 		}
-		return belaykit.Result{}, &ExitError{
+		return tracker.Result(), nil
+	}
+
+	// A CLI that handles SIGINT may still exit 0; the run was interrupted
+	// all the same.
+	waitErr := process.Wait()
+	if ctx.Err() != nil || tracker.Aborted() {
+		return tracker.Result(), tracker.InterruptError()
+	}
+	if err := waitErr; err != nil {
+		return tracker.Result(), &ExitError{
 			Err:    err,
 			Stderr: stderrBuf.String(),
 		}
 	}
 
-	return belaykit.Result{Text: resultText}, nil
+	return tracker.Result(), nil
 }
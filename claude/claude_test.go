@@ -1,9 +1,19 @@
 package claude
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"belaykit"
+	"belaykit/schema"
 )
 
 func TestNewClientDefaults(t *testing.T) {
@@ -82,3 +92,425 @@ func TestRunErrCLINotFound(t *testing.T) {
 	// Should get either ErrCLINotFound or an ExitError
 	// depending on the OS
 }
+
+func TestRunResultFromFakeExecutable(t *testing.T) {
+	exe := writeScript(t, "claude-success.sh", `#!/bin/sh
+echo '{"type":"system","subtype":"init","session_id":"sess-1","model":"claude-sonnet-4-5-20250929"}'
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","id":"tool_01","name":"Bash","input":{"command":"ls"}}]}}'
+echo '{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"tool_01","content":"main.go"}]}}'
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"done"}]}}'
+echo '{"type":"result","subtype":"success","result":"done","total_cost_usd":0.05,"duration_ms":1200,"num_turns":2,"usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":80,"cache_creation_input_tokens":10}}'
+`)
+
+	var record belaykit.CompletionRecord
+	c := NewClient(WithExecutable(exe), WithDefaultModel("sonnet"), WithObservability(recordingProvider{&record}))
+	res, err := c.Run(t.Context(), "hello")
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if res.Text != "done" || res.SessionID != "sess-1" || res.Subtype != "success" {
+		t.Errorf("result = %+v", res)
+	}
+	if res.Model != "claude-sonnet-4-5-20250929" {
+		t.Errorf("Model = %q, want reported model", res.Model)
+	}
+	if res.CostUSD != 0.05 || res.DurationMS != 1200 || res.NumTurns != 2 {
+		t.Errorf("cost/duration/turns = %v/%d/%d", res.CostUSD, res.DurationMS, res.NumTurns)
+	}
+	if res.InputTokens != 100 || res.OutputTokens != 20 || res.CacheReadTokens != 80 || res.CacheCreationTokens != 10 {
+		t.Errorf("tokens = %d/%d/%d/%d", res.InputTokens, res.OutputTokens, res.CacheReadTokens, res.CacheCreationTokens)
+	}
+	if len(res.ToolCalls) != 1 || res.ToolCalls[0].Name != "Bash" || res.ToolCalls[0].Output != "main.go" {
+		t.Errorf("ToolCalls = %+v", res.ToolCalls)
+	}
+	if record.SessionID != "sess-1" || record.InputTokens != 100 || record.OutputTokens != 20 {
+		t.Errorf("completion record = %+v", record)
+	}
+}
+
+type recordingProvider struct {
+	record *belaykit.CompletionRecord
+}
+
+func (p recordingProvider) StartSession(map[string]any) string                     { return "" }
+func (p recordingProvider) StartTrace(belaykit.TraceConfig, map[string]any) string { return "" }
+func (p recordingProvider) EndTrace(string, map[string]any)                        {}
+func (p recordingProvider) RecordCompletion(r belaykit.CompletionRecord)           { *p.record = r }
+
+func writeScript(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	return path
+}
+
+func TestRunResumeSession(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	exe := writeScript(t, "claude-resume.sh", `#!/bin/sh
+echo "$@" > `+argsFile+`
+`)
+
+	c := NewClient(WithExecutable(exe))
+	if _, err := c.Run(t.Context(), "continue", belaykit.WithResumeSession("sess-1")); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "--resume sess-1") {
+		t.Errorf("args = %q, want --resume sess-1", args)
+	}
+}
+
+func TestRunToolPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy belaykit.ToolPolicy
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "read only",
+			policy: belaykit.ToolPolicy{Access: belaykit.AccessReadOnly},
+			want:   []string{"--permission-mode plan", "--disallowedTools WebFetch", "--disallowedTools WebSearch"},
+		},
+		{
+			name:   "workspace write with patterns",
+			policy: belaykit.ToolPolicy{Access: belaykit.AccessWorkspaceWrite, Network: true, Allow: []string{"Bash(go test:*)"}, Deny: []string{"Bash(git push:*)"}},
+			want:   []string{"--permission-mode acceptEdits", "--allowedTools Bash(go test:*)", "--disallowedTools Bash(git push:*)"},
+			absent: []string{"WebFetch"},
+		},
+		{
+			name:   "full",
+			policy: belaykit.ToolPolicy{Access: belaykit.AccessFull, Network: true},
+			want:   []string{"--permission-mode bypassPermissions"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := filepath.Join(t.TempDir(), "args")
+			exe := writeScript(t, "claude-policy.sh", `#!/bin/sh
+echo "$@" > `+argsFile+`
+`)
+
+			c := NewClient(WithExecutable(exe))
+			if _, err := c.Run(t.Context(), "hello", belaykit.WithToolPolicy(&tt.policy)); err != nil {
+				t.Fatalf("Run error: %v", err)
+			}
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(args), want) {
+					t.Errorf("args = %q, want %q", args, want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(string(args), absent) {
+					t.Errorf("args = %q, want no %q", args, absent)
+				}
+			}
+		})
+	}
+}
+
+func TestRunInvalidToolPolicy(t *testing.T) {
+	c := NewClient(WithExecutable("false"))
+	_, err := c.Run(t.Context(), "hello", belaykit.WithToolPolicy(&belaykit.ToolPolicy{Access: "admin"}))
+	if !errors.Is(err, belaykit.ErrInvalidPolicy) {
+		t.Fatalf("expected ErrInvalidPolicy, got %v", err)
+	}
+}
+
+func TestRunOutputSchema(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	exe := writeScript(t, "claude-schema.sh", `#!/bin/sh
+echo "$@" > `+argsFile+`
+`)
+
+	type answer struct {
+		Verdict string `json:"verdict"`
+	}
+	c := NewClient(WithExecutable(exe))
+	if _, err := c.Run(t.Context(), "hello", belaykit.WithOutputSchema(schema.For[answer]())); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "--append-system-prompt Respond with a single JSON object") {
+		t.Errorf("args = %q, want schema in --append-system-prompt", args)
+	}
+}
+
+func TestRunWorkDirEnvAndAdditionalDirs(t *testing.T) {
+	workDir := t.TempDir()
+	outFile := filepath.Join(t.TempDir(), "out")
+	exe := writeScript(t, "claude-env.sh", `#!/bin/sh
+{ pwd; echo "$BELAYKIT_TEST_VAR"; echo "$CLAUDE_CODE_MAX_OUTPUT_TOKENS"; echo "$@"; } > `+outFile+`
+`)
+
+	c := NewClient(WithExecutable(exe))
+	_, err := c.Run(t.Context(), "hello",
+		belaykit.WithWorkDir(workDir),
+		belaykit.WithEnvOverrides(map[string]string{"BELAYKIT_TEST_VAR": "set"}),
+		belaykit.WithMaxOutputTokens(1000),
+		belaykit.WithAdditionalDirs("/tmp/shared"),
+	)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output = %q", data)
+	}
+	if want, _ := filepath.EvalSymlinks(workDir); lines[0] != want && lines[0] != workDir {
+		t.Errorf("pwd = %q, want %q", lines[0], workDir)
+	}
+	if lines[1] != "set" {
+		t.Errorf("BELAYKIT_TEST_VAR = %q, want %q", lines[1], "set")
+	}
+	if lines[2] != "1000" {
+		t.Errorf("CLAUDE_CODE_MAX_OUTPUT_TOKENS = %q, want %q", lines[2], "1000")
+	}
+	if !strings.Contains(lines[3], "--add-dir /tmp/shared") {
+		t.Errorf("args = %q, want --add-dir", lines[3])
+	}
+}
+
+func TestRunInterruptCleanExit(t *testing.T) {
+	exe := writeScript(t, "claude-interrupt-ok.sh", `#!/bin/sh
+trap 'exit 0' INT
+echo '{"type":"system","subtype":"init","session_id":"sess-1"}'
+while true; do sleep 0.05; done
+`)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	handler := func(e belaykit.Event) {
+		if e.Type == belaykit.EventSystem {
+			cancel()
+		}
+	}
+
+	c := NewClient(WithExecutable(exe))
+	_, err := c.Run(ctx, "hello", belaykit.WithEventHandler(handler))
+	if !errors.Is(err, belaykit.ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted though the CLI exited 0", err)
+	}
+}
+
+func TestRunInterruptReturnsPartialResult(t *testing.T) {
+	exe := writeScript(t, "claude-interrupt.sh", `#!/bin/sh
+trap 'echo "{\"type\":\"result\",\"subtype\":\"error_during_execution\",\"result\":\"partial\",\"total_cost_usd\":0.02,\"is_error\":true}"; exit 130' INT
+echo '{"type":"system","subtype":"init","session_id":"sess-1"}'
+while true; do sleep 0.05; done
+`)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	handler := func(e belaykit.Event) {
+		if e.Type == belaykit.EventSystem {
+			cancel()
+		}
+	}
+
+	c := NewClient(WithExecutable(exe))
+	res, err := c.Run(ctx, "hello", belaykit.WithEventHandler(handler), belaykit.WithGracePeriod(5*time.Second))
+	if !errors.Is(err, belaykit.ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want to wrap context.Canceled", err)
+	}
+	if res.SessionID != "sess-1" || res.Text != "partial" || res.CostUSD != 0.02 {
+		t.Errorf("partial result = %+v", res)
+	}
+}
+
+func TestRunGuardrailStopsCLI(t *testing.T) {
+	exe := writeScript(t, "claude-guardrail.sh", `#!/bin/sh
+trap 'echo "{\"type\":\"result\",\"subtype\":\"error_during_execution\",\"is_error\":true}"; exit 130' INT
+echo '{"type":"system","subtype":"init","session_id":"sess-1"}'
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"rm -rf ~"}}]}}'
+while true; do sleep 0.05; done
+`)
+
+	var types []belaykit.EventType
+	handler := func(e belaykit.Event) { types = append(types, e.Type) }
+
+	c := NewClient(WithExecutable(exe))
+	_, err := c.Run(t.Context(), "clean up",
+		belaykit.WithEventHandler(handler),
+		belaykit.WithGuardrails(belaykit.CommandRule("no-rm-rf", `rm\s+-rf`)),
+	)
+	if !errors.Is(err, belaykit.ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted", err)
+	}
+	var gerr *belaykit.GuardrailError
+	if !errors.As(err, &gerr) || gerr.Rule != "no-rm-rf" || gerr.ToolID != "toolu_1" {
+		t.Fatalf("err = %v, want GuardrailError for no-rm-rf", err)
+	}
+	if !slices.Contains(types, belaykit.EventGuardrail) {
+		t.Errorf("event types = %v, want a guardrail event", types)
+	}
+}
+
+func TestRunBudgetExceeded(t *testing.T) {
+	exe := writeScript(t, "claude-budget.sh", `#!/bin/sh
+trap 'exit 130' INT
+echo '{"type":"system","subtype":"init","session_id":"sess-1"}'
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"a very long answer that costs a lot"}]}}'
+while true; do sleep 0.05; done
+`)
+
+	c := NewClient(WithExecutable(exe))
+	_, err := c.Run(t.Context(), "hello",
+		belaykit.WithMaxCostUSD(0.01),
+		belaykit.WithModelPricing(belaykit.ModelPricing{OutputPerMTok: 1_000_000}),
+	)
+	if !errors.Is(err, belaykit.ErrInterrupted) || !errors.Is(err, belaykit.ErrBudgetExceeded) {
+		t.Fatalf("err = %v, want interrupted budget error", err)
+	}
+
+	// An exhausted shared budget refuses to start the run at all.
+	budget := belaykit.NewBudget(0)
+	_, err = c.Run(t.Context(), "hello", belaykit.WithBudget(budget))
+	var berr *belaykit.BudgetExceededError
+	if !errors.As(err, &berr) || errors.Is(err, belaykit.ErrInterrupted) {
+		t.Fatalf("err = %v, want BudgetExceededError before start", err)
+	}
+}
+
+func TestRunToolTimeout(t *testing.T) {
+	exe := writeScript(t, "claude-stall.sh", `#!/bin/sh
+trap 'exit 130' INT
+echo '{"type":"system","subtype":"init","session_id":"sess-1"}'
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","id":"tool_01","name":"Bash","input":{"command":"sleep 999"}}]}}'
+while true; do sleep 0.05; done
+`)
+
+	c := NewClient(WithExecutable(exe))
+	res, err := c.Run(t.Context(), "hello", belaykit.WithToolTimeout(100*time.Millisecond))
+	var serr *belaykit.StallError
+	if !errors.Is(err, belaykit.ErrInterrupted) || !errors.As(err, &serr) {
+		t.Fatalf("err = %v, want interrupted StallError", err)
+	}
+	if serr.ToolName != "Bash" || serr.ToolID != "tool_01" {
+		t.Errorf("StallError = %+v", serr)
+	}
+	if res.SessionID != "sess-1" || len(res.ToolCalls) != 1 {
+		t.Errorf("partial result = %+v", res)
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	var types []belaykit.EventType
+	var record belaykit.CompletionRecord
+	c := NewClient(WithReplay("testdata/tool_run.jsonl"), WithObservability(recordingProvider{&record}))
+	res, err := c.Run(t.Context(), "why does TestParse pass?", belaykit.WithEventHandler(func(e belaykit.Event) {
+		types = append(types, e.Type)
+	}))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if res.Text != "The test is empty, so it cannot fail." || res.Model != "claude-sonnet-4-5-20250929" {
+		t.Errorf("result = %+v", res)
+	}
+	if res.CostUSD != 0.0213 || res.NumTurns != 3 || res.CacheReadTokens != 20480 {
+		t.Errorf("cost/turns/cache = %v/%d/%d", res.CostUSD, res.NumTurns, res.CacheReadTokens)
+	}
+	if len(res.ToolCalls) != 2 || res.ToolCalls[1].Name != "Read" || !strings.Contains(res.ToolCalls[1].Output, "package parse") {
+		t.Errorf("ToolCalls = %+v", res.ToolCalls)
+	}
+	want := []belaykit.EventType{
+		belaykit.EventSystem, belaykit.EventAssistantStart,
+		belaykit.EventAssistant, belaykit.EventToolUse, belaykit.EventToolResult, belaykit.EventAssistantStart,
+		belaykit.EventToolUse, belaykit.EventToolResult, belaykit.EventAssistantStart,
+		belaykit.EventAssistant, belaykit.EventResult,
+	}
+	if !slices.Equal(types, want) {
+		t.Errorf("events = %v\nwant %v", types, want)
+	}
+	if record.SessionID != "4f1c2a9e-7b1d-4a52-9f0e-2c6d8e1b3a77" {
+		t.Errorf("completion record = %+v", record)
+	}
+}
+
+func TestRunRecordThenReplay(t *testing.T) {
+	exe := writeScript(t, "claude-record.sh", `#!/bin/sh
+echo '{"type":"system","subtype":"init","session_id":"sess-1","model":"claude-sonnet-4-5-20250929"}'
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","id":"tool_01","name":"Bash","input":{"command":"ls"}}]}}'
+echo '{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"tool_01","content":"main.go"}]}}'
+echo '{"type":"result","subtype":"success","result":"done","total_cost_usd":0.05,"duration_ms":1200,"num_turns":2}'
+`)
+	var cassette bytes.Buffer
+	live, err := NewClient(WithExecutable(exe)).Run(t.Context(), "hello", belaykit.WithRecorder(&cassette))
+	if err != nil {
+		t.Fatalf("live Run error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	if err := os.WriteFile(path, cassette.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := NewClient(WithReplay(path)).Run(t.Context(), "hello")
+	if err != nil {
+		t.Fatalf("replay Run error: %v", err)
+	}
+	live.RunID, replayed.RunID = "", "" // each run has its own ID
+	if !reflect.DeepEqual(live, replayed) {
+		t.Errorf("replayed = %+v\nlive = %+v", replayed, live)
+	}
+}
+
+func TestRunReplayMissingCassette(t *testing.T) {
+	c := NewClient(WithReplay(filepath.Join(t.TempDir(), "missing.jsonl")))
+	if _, err := c.Run(t.Context(), "hello"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("err = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	exe := writeScript(t, "claude-start.sh", `#!/bin/sh
+trap 'exit 130' INT
+echo '{"type":"system","subtype":"init","session_id":"sess-1","model":"claude-sonnet-4-5"}'
+while true; do
+  echo '{"type":"assistant","message":{"content":[{"type":"text","text":"working on it"}]}}'
+  sleep 0.05
+done
+`)
+
+	c := NewClient(WithExecutable(exe))
+	h := c.Start(t.Context(), "hello", belaykit.WithGracePeriod(time.Second))
+	for e := range h.Events() {
+		if e.Type == belaykit.EventAssistant {
+			stats := h.Stats()
+			if stats.SessionID != "sess-1" || stats.Done || stats.CostUSD <= 0 {
+				t.Errorf("live Stats = %+v, want session and estimated cost", stats)
+			}
+			h.Interrupt()
+		}
+	}
+
+	res, err := h.Wait()
+	if !errors.Is(err, belaykit.ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted", err)
+	}
+	if res.SessionID != "sess-1" || h.SessionID() != "sess-1" {
+		t.Errorf("Result.SessionID = %q, handle SessionID = %q", res.SessionID, h.SessionID())
+	}
+	if !h.Stats().Done {
+		t.Error("Stats.Done is false after Wait")
+	}
+}
@@ -0,0 +1,57 @@
+//go:build unix
+
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"belaykit"
+)
+
+func TestRunStreamBreakStopsCLI(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	exe := writeScript(t, "claude-stream.sh", `#!/bin/sh
+echo $$ > `+pidFile+`
+trap 'exit 130' INT
+echo '{"type":"system","subtype":"init","session_id":"sess-1"}'
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"first"}]}}'
+while true; do sleep 0.05; done
+`)
+
+	c := NewClient(WithExecutable(exe))
+	var text string
+	var res belaykit.Result
+	for ev, err := range belaykit.RunStream(t.Context(), c, "hello", &res, belaykit.WithGracePeriod(time.Second)) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		if ev.Type == belaykit.EventAssistant {
+			text = ev.Text
+			break
+		}
+	}
+	if text != "first" {
+		t.Errorf("text = %q, want first", text)
+	}
+	if res.SessionID != "sess-1" {
+		t.Errorf("Result.SessionID = %q, want the partial result", res.SessionID)
+	}
+
+	pid, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(pid)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(n, 0); err == nil {
+		t.Error("CLI process still running after the loop was broken")
+	}
+}
@@ -0,0 +1,27 @@
+package claude
+
+import (
+	"fmt"
+	"testing"
+
+	"belaykit"
+	"belaykit/belaykittest"
+)
+
+func TestConformance(t *testing.T) {
+	belaykittest.RunConformance(t, func(t *testing.T, s belaykittest.Scenario) belaykit.Agent {
+		script := fmt.Sprintf("#!/bin/sh\ntrap 'exit 130' INT\necho '{\"type\":\"system\",\"subtype\":\"init\",\"session_id\":%q}'\n", s.SessionID)
+		switch s.Behavior {
+		case belaykittest.Reply:
+			script += fmt.Sprintf(`echo '{"type":"assistant","message":{"content":[{"type":"text","text":%q}]}}'
+echo '{"type":"result","subtype":"success","result":%q,"total_cost_usd":0.01,"num_turns":1}'
+`, s.Text, s.Text)
+		case belaykittest.Hang:
+			script += "while true; do sleep 0.05; done\n"
+		case belaykittest.Fail:
+			script += "echo 'API Error: 429 rate limit exceeded' >&2\nexit 1\n"
+		}
+		exe := writeScript(t, "claude-conformance.sh", script)
+		return NewClient(WithExecutable(exe), WithObservability(s.Observability))
+	})
+}
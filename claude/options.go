@@ -34,3 +34,24 @@ func WithObservability(provider belaykit.ObservabilityProvider) ClientOption {
 		c.observability = provider
 	}
 }
+
+// WithToolApprover routes the CLI's permission prompts to approver: every
+// tool call that is not already allowed (see belaykit.WithAllowedTools and
+// belaykit.WithToolPolicy) waits for its Decision. The CLI reaches approver
+// through an MCP server that each run serves over HTTP on 127.0.0.1. Each
+// decision is emitted as a belaykit.EventApproval event.
+func WithToolApprover(approver ToolApprover) ClientOption {
+	return func(c *Client) {
+		c.approver = approver
+	}
+}
+
+// WithReplay makes the client read stream-json output from a cassette file
+// instead of running the CLI. The cassette is parsed exactly like live
+// output, so a transcript recorded with belaykit.WithRecorder reproduces the
+// original run's events and Result.
+func WithReplay(path string) ClientOption {
+	return func(c *Client) {
+		c.replayPath = path
+	}
+}
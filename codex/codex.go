@@ -14,13 +14,18 @@ import (
 	"sync"
 
 	"belaykit"
+	"belaykit/internal/proc"
+	"belaykit/schema"
 )
 
-// Verify Client implements belaykit.Agent.
-var _ belaykit.Agent = (*Client)(nil)
+// Verify Client implements belaykit.Agent and belaykit.Starter.
+var (
+	_ belaykit.Agent   = (*Client)(nil)
+	_ belaykit.Starter = (*Client)(nil)
+)
 
 // ErrCLINotFound indicates the codex CLI binary was not found on PATH.
-var ErrCLINotFound = errors.New("codex CLI not found")
+var ErrCLINotFound = fmt.Errorf("codex %w", belaykit.ErrCLINotFound)
 
 // UnsupportedOptionError indicates a run option not yet supported by codex.
 type UnsupportedOptionError struct {
@@ -48,12 +53,18 @@ func (e *ExitError) Unwrap() error {
 	return e.Err
 }
 
+// CLIStderr returns the captured stderr, implementing belaykit.CLIError.
+func (e *ExitError) CLIStderr() string {
+	return e.Stderr
+}
+
 // Client wraps the Codex CLI for non-interactive invocations.
 type Client struct {
 	executable    string
 	defaultModel  string
 	eventHandler  belaykit.EventHandler
 	observability belaykit.ObservabilityProvider
+	replayPath    string
 }
 
 // NewClient creates a new Codex CLI client.
@@ -67,13 +78,45 @@ func NewClient(opts ...ClientOption) *Client {
 	return c
 }
 
+// Name returns the provider name, "codex".
+func (c *Client) Name() string {
+	return "codex"
+}
+
+// DefaultEventHandler returns the handler set with WithDefaultEventHandler,
+// so wrappers such as belaykit.Retry can send their own events to it.
+func (c *Client) DefaultEventHandler() belaykit.EventHandler {
+	return c.eventHandler
+}
+
+// EnforcesOutputSchema reports that the client passes
+// belaykit.WithOutputSchema to the CLI with --output-schema, so
+// belaykit.RunTyped leaves it out of the prompt.
+func (c *Client) EnforcesOutputSchema() bool {
+	return true
+}
+
+// Start runs the Codex CLI in the background and returns a handle to the
+// run.
+func (c *Client) Start(ctx context.Context, prompt string, opts ...belaykit.RunOption) *belaykit.RunHandle {
+	return belaykit.StartRun(ctx, c, prompt, opts...)
+}
+
 // Run executes the Codex CLI with the given prompt and returns the result.
+// When ctx is done the CLI is interrupted with a grace period (see
+// belaykit.WithGracePeriod), and Run returns the partial Result and an error
+// wrapping belaykit.ErrInterrupted. The same happens when the run exceeds a
+// cost limit; pass belaykit.WithModelPricing to enforce limits before codex
+// reports a cost.
 func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOption) (belaykit.Result, error) {
 	cfg := belaykit.NewRunConfig(opts...)
 
 	if err := validateRunConfig(cfg); err != nil {
 		return belaykit.Result{}, err
 	}
+	if err := cfg.CheckBudget(); err != nil {
+		return belaykit.Result{}, err
+	}
 
 	model := c.defaultModel
 	if cfg.Model != "" {
@@ -93,36 +136,74 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 	if model != "" {
 		args = append(args, "-m", model)
 	}
+	if cfg.OutputSchema != nil {
+		schemaPath, err := writeSchemaFile(cfg.OutputSchema)
+		if err != nil {
+			return belaykit.Result{}, err
+		}
+		defer os.Remove(schemaPath)
+		args = append(args, "--output-schema", schemaPath)
+	}
+	if cfg.WorkDir != "" {
+		args = append(args, "-C", cfg.WorkDir)
+	}
+	for _, dir := range cfg.AdditionalDirs {
+		args = append(args, "--add-dir", dir)
+	}
+	if cfg.ToolPolicy != nil {
+		args = append(args, policyArgs(*cfg.ToolPolicy)...)
+	}
+	if cfg.ResumeSessionID != "" {
+		args = append(args, "resume", cfg.ResumeSessionID)
+	}
 	args = append(args, composedPrompt)
 
-	cmd := exec.CommandContext(ctx, c.executable, args...)
+	handler := cfg.ResolveEventHandler(c.eventHandler)
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return belaykit.Result{}, fmt.Errorf("creating stdout pipe: %w", err)
-	}
+	ctx, tracker := belaykit.BeginRun(ctx, cfg, model, handler)
+	defer tracker.End()
+	emit := tracker.Emit
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return belaykit.Result{}, fmt.Errorf("creating stderr pipe: %w", err)
-	}
+	var stdout, stderr io.Reader
+	var process *proc.Process
+	if c.replayPath != "" {
+		cassette, err := os.Open(c.replayPath)
+		if err != nil {
+			return belaykit.Result{}, fmt.Errorf("opening replay cassette: %w", err)
+		}
+		defer cassette.Close()
+		stdout, stderr = cassette, strings.NewReader("")
+	} else {
+		cmd := exec.Command(c.executable, args...)
+		cmd.Dir = cfg.WorkDir
+		cmd.Env = cfg.CommandEnv()
+
+		stdout, err = cmd.StdoutPipe()
+		if err != nil {
+			return belaykit.Result{}, fmt.Errorf("creating stdout pipe: %w", err)
+		}
 
-	if err := cmd.Start(); err != nil {
-		if errors.Is(err, exec.ErrNotFound) {
-			return belaykit.Result{}, ErrCLINotFound
+		stderr, err = cmd.StderrPipe()
+		if err != nil {
+			return belaykit.Result{}, fmt.Errorf("creating stderr pipe: %w", err)
 		}
-		return belaykit.Result{}, &ExitError{Err: err}
-	}
 
-	handler := c.eventHandler
-	if cfg.EventHandler != nil {
-		handler = cfg.EventHandler
+		process, err = proc.Start(ctx, cmd, cfg.GracePeriod)
+		if err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return belaykit.Result{}, ErrCLINotFound
+			}
+			return belaykit.Result{}, &ExitError{Err: err}
+		}
 	}
 
 	var stderrBuf bytes.Buffer
 	state := runState{}
 	lines := streamLines(stdout, stderr)
 	for line := range lines {
+		if process == nil && ctx.Err() != nil {
+			continue // a replay has no process to interrupt; drain the cassette
+		}
 		if !json.Valid(line.body) {
 			if line.fromStderr {
 				stderrBuf.Write(line.body)
@@ -130,32 +211,36 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 			}
 			continue
 		}
-		state.handleJSONLine(line.body, handler, cfg.OutputStream)
+		// Replay feeds the cassette to stdout, so only stdout is recorded.
+		if cfg.Recorder != nil && !line.fromStderr {
+			cfg.Recorder.Write(append(line.body[:len(line.body):len(line.body)], '\n'))
+		}
+		state.handleJSONLine(line.body, emit, cfg.OutputStream)
 	}
 
-	if err := cmd.Wait(); err != nil {
+	var waitErr error
+	if process == nil {
 		if ctx.Err() != nil {
-			return belaykit.Result{}, ctx.Err()
+			return tracker.Result(), tracker.InterruptError()
 		}
-
-		if handler != nil && !state.resultEmitted {
-			handler(belaykit.Event{Type: belaykit.EventResultError, Text: state.lastError})
+	} else {
+		// A CLI that handles SIGINT may still exit 0; the run was
+		// interrupted all the same.
+		waitErr = process.Wait()
+		if ctx.Err() != nil || tracker.Aborted() {
+			return tracker.Result(), tracker.InterruptError()
+		}
+	}
+	if err := waitErr; err != nil {
+		if !state.resultEmitted {
+			emit(state.resultEvent(belaykit.EventResultError, state.lastError))
 		}
+		res := tracker.Result()
 		if c.observability != nil {
-			c.observability.RecordCompletion(belaykit.CompletionRecord{
-				TraceID:    cfg.TraceID,
-				SessionID:  state.sessionID,
-				Prompt:     prompt,
-				Response:   state.lastError,
-				Model:      model,
-				CostUSD:    state.costUSD,
-				DurationMS: state.durationMS,
-				NumTurns:   state.numTurns,
-				IsError:    true,
-			})
+			c.observability.RecordCompletion(completionRecord(cfg, prompt, res))
 		}
 
-		return belaykit.Result{}, &ExitError{Err: err, Stderr: stderrBuf.String()}
+		return res, &ExitError{Err: err, Stderr: stderrBuf.String()}
 	}
 
 	resultText := state.assistantText.String()
@@ -163,30 +248,38 @@ func (c *Client) Run(ctx context.Context, prompt string, opts ...belaykit.RunOpt
 		resultText = string(data)
 	}
 
-	if handler != nil && !state.resultEmitted {
-		handler(belaykit.Event{
-			Type:     belaykit.EventResult,
-			Text:     resultText,
-			CostUSD:  state.costUSD,
-			Duration: state.durationMS,
-			NumTurns: state.numTurns,
-		})
+	if !state.resultEmitted {
+		emit(state.resultEvent(belaykit.EventResult, resultText))
 	}
+	res := tracker.Result()
+	// A turn.failed event may have already reported the error text; the
+	// final message file is still the authoritative response.
+	res.Text = resultText
 	if c.observability != nil {
-		c.observability.RecordCompletion(belaykit.CompletionRecord{
-			TraceID:    cfg.TraceID,
-			SessionID:  state.sessionID,
-			Prompt:     prompt,
-			Response:   resultText,
-			Model:      model,
-			CostUSD:    state.costUSD,
-			DurationMS: state.durationMS,
-			NumTurns:   state.numTurns,
-			IsError:    false,
-		})
-	}
-
-	return belaykit.Result{Text: resultText}, nil
+		c.observability.RecordCompletion(completionRecord(cfg, prompt, res))
+	}
+
+	return res, nil
+}
+
+func completionRecord(cfg belaykit.RunConfig, prompt string, res belaykit.Result) belaykit.CompletionRecord {
+	return belaykit.CompletionRecord{
+		TraceID:      cfg.TraceID,
+		JobID:        cfg.JobID,
+		RunID:        res.RunID,
+		ParentRunID:  cfg.ParentRunID,
+		SessionID:    res.SessionID,
+		Provider:     "codex",
+		Prompt:       prompt,
+		Response:     res.Text,
+		Model:        res.Model,
+		CostUSD:      res.CostUSD,
+		DurationMS:   res.DurationMS,
+		NumTurns:     res.NumTurns,
+		IsError:      res.IsError,
+		InputTokens:  res.InputTokens,
+		OutputTokens: res.OutputTokens,
+	}
 }
 
 func validateRunConfig(cfg belaykit.RunConfig) error {
@@ -202,9 +295,67 @@ func validateRunConfig(cfg belaykit.RunConfig) error {
 	if len(cfg.DisallowedTools) > 0 {
 		return &UnsupportedOptionError{Option: "WithDisallowedTools"}
 	}
+	if p := cfg.ToolPolicy; p != nil {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		// Codex cannot restrict individual tools.
+		if len(p.Allow) > 0 {
+			return &UnsupportedOptionError{Option: "ToolPolicy.Allow"}
+		}
+		if len(p.Deny) > 0 {
+			return &UnsupportedOptionError{Option: "ToolPolicy.Deny"}
+		}
+		// The read-only sandbox always blocks the network.
+		if p.Access == belaykit.AccessReadOnly && p.Network {
+			return &UnsupportedOptionError{Option: "ToolPolicy.Network"}
+		}
+	}
 	return nil
 }
 
+// sandboxModes maps policy access levels to codex sandbox modes.
+var sandboxModes = map[belaykit.Access]string{
+	belaykit.AccessReadOnly:       "read-only",
+	belaykit.AccessWorkspaceWrite: "workspace-write",
+	belaykit.AccessFull:           "danger-full-access",
+}
+
+// policyArgs translates a validated ToolPolicy into CLI flags. Runs are
+// unattended, so codex never asks for approval: commands the sandbox does
+// not permit fail instead.
+func policyArgs(p belaykit.ToolPolicy) []string {
+	args := []string{
+		"--sandbox", sandboxModes[p.Access],
+		"-c", `approval_policy="never"`,
+	}
+	if p.Access == belaykit.AccessWorkspaceWrite {
+		args = append(args, "-c", fmt.Sprintf("sandbox_workspace_write.network_access=%t", p.Network))
+	}
+	return args
+}
+
+// writeSchemaFile writes s to a temp file for --output-schema and returns
+// its path.
+func writeSchemaFile(s *schema.Schema) (string, error) {
+	// Codex enforces the schema in strict mode, which rejects optional
+	// properties, maps and untyped values.
+	strict, err := s.Strict()
+	if err != nil {
+		return "", fmt.Errorf("codex output schema: %w", err)
+	}
+	f, err := os.CreateTemp("", "belaykit-codex-schema-*.json")
+	if err != nil {
+		return "", fmt.Errorf("creating schema file: %w", err)
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(strict); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("writing schema file: %w", err)
+	}
+	return f.Name(), nil
+}
+
 func composePrompt(systemPrompt, prompt string) string {
 	if systemPrompt == "" {
 		return prompt
@@ -219,7 +370,26 @@ type runState struct {
 	costUSD       float64
 	durationMS    int64
 	numTurns      int
+	inputTokens   int
+	cachedTokens  int
+	outputTokens  int
 	resultEmitted bool
+	openTools     map[string]bool // item IDs with an emitted tool_use awaiting a tool_result
+}
+
+// resultEvent builds the terminal event for the run from the accumulated state.
+func (s *runState) resultEvent(evType belaykit.EventType, text string) belaykit.Event {
+	return belaykit.Event{
+		Type:            evType,
+		Text:            text,
+		CostUSD:         s.costUSD,
+		Duration:        s.durationMS,
+		NumTurns:        s.numTurns,
+		IsError:         evType == belaykit.EventResultError,
+		InputTokens:     s.inputTokens,
+		OutputTokens:    s.outputTokens,
+		CacheReadTokens: s.cachedTokens,
+	}
 }
 
 func (s *runState) handleJSONLine(line []byte, handler belaykit.EventHandler, outputStream io.Writer) {
@@ -252,6 +422,20 @@ func (s *runState) handleJSONLine(line []byte, handler belaykit.EventHandler, ou
 				RawJSON: raw,
 			})
 		}
+	case "turn.completed":
+		if usage, ok := payload["usage"].(map[string]any); ok {
+			if v, ok := int64Field(usage, "input_tokens"); ok {
+				s.inputTokens += int(v)
+			}
+			if v, ok := int64Field(usage, "cached_input_tokens"); ok {
+				s.cachedTokens += int(v)
+			}
+			if v, ok := int64Field(usage, "output_tokens"); ok {
+				s.outputTokens += int(v)
+			}
+		}
+	case "item.started", "item.completed":
+		s.handleToolItem(eventType, payload, handler, raw)
 	case "turn.failed":
 		msg := extractErrorMessage(payload)
 		if msg == "" {
@@ -291,7 +475,71 @@ func (s *runState) handleJSONLine(line []byte, handler belaykit.EventHandler, ou
 	}
 }
 
+// toolItemTypes are the codex item types that represent tool invocations.
+var toolItemTypes = map[string]bool{
+	"command_execution": true,
+	"mcp_tool_call":     true,
+	"file_change":       true,
+	"web_search":        true,
+}
+
+// handleToolItem maps codex tool items onto tool_use/tool_result events.
+// Items that only report completion get both events back to back.
+func (s *runState) handleToolItem(eventType string, payload map[string]any, handler belaykit.EventHandler, raw json.RawMessage) {
+	item, ok := payload["item"].(map[string]any)
+	if !ok {
+		return
+	}
+	itemType, _ := item["type"].(string)
+	if !toolItemTypes[itemType] {
+		return
+	}
+	id, _ := item["id"].(string)
+	if s.openTools == nil {
+		s.openTools = make(map[string]bool)
+	}
+
+	if !s.openTools[id] {
+		s.openTools[id] = true
+		name := itemType
+		if tool, _ := item["tool"].(string); tool != "" {
+			name = tool
+		}
+		input, _ := json.Marshal(item)
+		if handler != nil {
+			handler(belaykit.Event{
+				Type:      belaykit.EventToolUse,
+				ToolName:  name,
+				ToolID:    id,
+				ToolInput: input,
+				RawJSON:   raw,
+			})
+		}
+	}
+
+	if eventType == "item.completed" {
+		delete(s.openTools, id)
+		output, _ := item["aggregated_output"].(string)
+		if handler != nil {
+			handler(belaykit.Event{
+				Type:    belaykit.EventToolResult,
+				Text:    output,
+				ToolID:  id,
+				RawJSON: raw,
+			})
+		}
+	}
+}
+
 func extractAssistantText(eventType string, payload map[string]any) string {
+	// codex exec --json reports each finished reply as an agent_message item.
+	if eventType == "item.completed" {
+		if item, ok := payload["item"].(map[string]any); ok && item["type"] == "agent_message" {
+			text, _ := item["text"].(string)
+			return text
+		}
+	}
+
 	if !looksLikeAssistantEvent(eventType) {
 		return ""
 	}
@@ -2,12 +2,17 @@ package codex
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"belaykit"
+	"belaykit/schema"
 )
 
 func TestNewClientDefaults(t *testing.T) {
@@ -58,6 +63,9 @@ func TestRunErrCLINotFound(t *testing.T) {
 	if !errors.Is(err, ErrCLINotFound) {
 		t.Fatalf("expected ErrCLINotFound, got %v", err)
 	}
+	if !errors.Is(err, belaykit.ErrCLINotFound) {
+		t.Fatalf("expected belaykit.ErrCLINotFound, got %v", err)
+	}
 }
 
 func TestRunUnsupportedOptions(t *testing.T) {
@@ -70,6 +78,9 @@ func TestRunUnsupportedOptions(t *testing.T) {
 		{name: "max output", opts: []belaykit.RunOption{belaykit.WithMaxOutputTokens(100)}, want: "WithMaxOutputTokens"},
 		{name: "allowed tools", opts: []belaykit.RunOption{belaykit.WithAllowedTools("Bash(*)")}, want: "WithAllowedTools"},
 		{name: "disallowed tools", opts: []belaykit.RunOption{belaykit.WithDisallowedTools("Write(*)")}, want: "WithDisallowedTools"},
+		{name: "policy allow", opts: []belaykit.RunOption{belaykit.WithToolPolicy(&belaykit.ToolPolicy{Access: belaykit.AccessReadOnly, Allow: []string{"Read"}})}, want: "ToolPolicy.Allow"},
+		{name: "policy deny", opts: []belaykit.RunOption{belaykit.WithToolPolicy(&belaykit.ToolPolicy{Access: belaykit.AccessReadOnly, Deny: []string{"Bash"}})}, want: "ToolPolicy.Deny"},
+		{name: "read-only network", opts: []belaykit.RunOption{belaykit.WithToolPolicy(&belaykit.ToolPolicy{Access: belaykit.AccessReadOnly, Network: true})}, want: "ToolPolicy.Network"},
 	}
 
 	c := NewClient(WithExecutable("true"))
@@ -205,3 +216,314 @@ func writeScript(t *testing.T, name, body string) string {
 	}
 	return path
 }
+
+func TestRunResultFromFakeExecutable(t *testing.T) {
+	exe := writeScript(t, "codex-result.sh", `#!/bin/sh
+out=""
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then
+    out="$2"
+    shift 2
+    continue
+  fi
+  shift
+done
+echo '{"type":"thread.started","thread_id":"thread-123"}'
+echo '{"type":"turn.started"}'
+echo '{"type":"item.started","item":{"id":"item_1","type":"command_execution","command":"ls"}}'
+echo '{"type":"item.completed","item":{"id":"item_1","type":"command_execution","command":"ls","aggregated_output":"main.go"}}'
+echo '{"type":"item.completed","item":{"id":"item_2","type":"file_change","changes":[]}}'
+echo '{"type":"turn.completed","usage":{"input_tokens":100,"cached_input_tokens":40,"output_tokens":25}}'
+printf 'done' > "$out"
+`)
+
+	c := NewClient(WithExecutable(exe), WithDefaultModel("gpt-5-codex"))
+	res, err := c.Run(t.Context(), "hello")
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if res.Text != "done" || res.SessionID != "thread-123" || res.Model != "gpt-5-codex" {
+		t.Errorf("result = %+v", res)
+	}
+	if res.NumTurns != 1 || res.IsError {
+		t.Errorf("NumTurns = %d IsError = %v", res.NumTurns, res.IsError)
+	}
+	if res.InputTokens != 100 || res.CacheReadTokens != 40 || res.OutputTokens != 25 {
+		t.Errorf("tokens = %d/%d/%d", res.InputTokens, res.CacheReadTokens, res.OutputTokens)
+	}
+	if len(res.ToolCalls) != 2 {
+		t.Fatalf("ToolCalls = %+v, want 2 calls", res.ToolCalls)
+	}
+	if tc := res.ToolCalls[0]; tc.ID != "item_1" || tc.Name != "command_execution" || tc.Output != "main.go" {
+		t.Errorf("ToolCalls[0] = %+v", tc)
+	}
+	if tc := res.ToolCalls[1]; tc.Name != "file_change" {
+		t.Errorf("ToolCalls[1] = %+v", tc)
+	}
+}
+
+func TestRunResumeSession(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	exe := writeScript(t, "codex-resume.sh", `#!/bin/sh
+echo "$@" > `+argsFile+`
+echo '{"type":"thread.started","thread_id":"thread-123"}'
+`)
+
+	c := NewClient(WithExecutable(exe))
+	if _, err := c.Run(t.Context(), "continue", belaykit.WithResumeSession("thread-123")); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(args, []byte("resume thread-123 continue")) {
+		t.Errorf("args = %q, want resume subcommand before prompt", args)
+	}
+}
+
+func TestRunWorkDirAndAdditionalDirs(t *testing.T) {
+	workDir := t.TempDir()
+	argsFile := filepath.Join(t.TempDir(), "args")
+	exe := writeScript(t, "codex-dirs.sh", `#!/bin/sh
+echo "$BELAYKIT_TEST_VAR $@" > `+argsFile+`
+`)
+
+	c := NewClient(WithExecutable(exe))
+	_, err := c.Run(t.Context(), "hello",
+		belaykit.WithWorkDir(workDir),
+		belaykit.WithEnv("BELAYKIT_TEST_VAR=only"),
+		belaykit.WithAdditionalDirs("/tmp/shared"),
+	)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"only ", "-C " + workDir, "--add-dir /tmp/shared"} {
+		if !bytes.Contains(args, []byte(want)) {
+			t.Errorf("args = %q, want %q", args, want)
+		}
+	}
+}
+
+func TestRunToolPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy belaykit.ToolPolicy
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "read only",
+			policy: belaykit.ToolPolicy{Access: belaykit.AccessReadOnly},
+			want:   []string{"--sandbox read-only", `-c approval_policy="never"`},
+			absent: []string{"network_access"},
+		},
+		{
+			name:   "workspace write",
+			policy: belaykit.ToolPolicy{Access: belaykit.AccessWorkspaceWrite},
+			want:   []string{"--sandbox workspace-write", "sandbox_workspace_write.network_access=false"},
+		},
+		{
+			name:   "workspace write with network",
+			policy: belaykit.ToolPolicy{Access: belaykit.AccessWorkspaceWrite, Network: true},
+			want:   []string{"sandbox_workspace_write.network_access=true"},
+		},
+		{
+			name:   "full",
+			policy: belaykit.ToolPolicy{Access: belaykit.AccessFull, Network: true},
+			want:   []string{"--sandbox danger-full-access"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := filepath.Join(t.TempDir(), "args")
+			exe := writeScript(t, "codex-policy.sh", `#!/bin/sh
+echo "$@" > `+argsFile+`
+`)
+
+			c := NewClient(WithExecutable(exe))
+			if _, err := c.Run(t.Context(), "hello", belaykit.WithToolPolicy(&tt.policy)); err != nil {
+				t.Fatalf("Run error: %v", err)
+			}
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !bytes.Contains(args, []byte(want)) {
+					t.Errorf("args = %q, want %q", args, want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if bytes.Contains(args, []byte(absent)) {
+					t.Errorf("args = %q, want no %q", args, absent)
+				}
+			}
+		})
+	}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	exe := writeScript(t, "codex-interrupt.sh", `#!/bin/sh
+trap 'exit 130' INT
+echo '{"type":"thread.started","thread_id":"thread-123"}'
+while true; do sleep 0.05; done
+`)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	handler := func(e belaykit.Event) {
+		if e.Type == belaykit.EventSystem {
+			cancel()
+		}
+	}
+
+	c := NewClient(WithExecutable(exe))
+	res, err := c.Run(ctx, "hello", belaykit.WithEventHandler(handler))
+	if !errors.Is(err, belaykit.ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted", err)
+	}
+	if res.SessionID != "thread-123" {
+		t.Errorf("SessionID = %q, want partial result", res.SessionID)
+	}
+}
+
+func TestRunInterruptCleanExit(t *testing.T) {
+	exe := writeScript(t, "codex-interrupt-ok.sh", `#!/bin/sh
+trap 'exit 0' INT
+echo '{"type":"thread.started","thread_id":"thread-123"}'
+while true; do sleep 0.05; done
+`)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	handler := func(e belaykit.Event) {
+		if e.Type == belaykit.EventSystem {
+			cancel()
+		}
+	}
+
+	c := NewClient(WithExecutable(exe))
+	_, err := c.Run(ctx, "hello", belaykit.WithEventHandler(handler))
+	if !errors.Is(err, belaykit.ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted though the CLI exited 0", err)
+	}
+}
+
+func TestRunOutputSchema(t *testing.T) {
+	schemaCopy := filepath.Join(t.TempDir(), "schema.json")
+	exe := writeScript(t, "codex-schema.sh", `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--output-schema" ]; then cp "$2" `+schemaCopy+`; fi
+  shift
+done
+`)
+
+	type answer struct {
+		Verdict string   `json:"verdict" enum:"yes,no"`
+		Issues  []string `json:"issues,omitempty"`
+		Score   *float64 `json:"score"`
+	}
+	c := NewClient(WithExecutable(exe))
+	if _, err := c.Run(t.Context(), "hello", belaykit.WithOutputSchema(schema.For[answer]())); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	data, err := os.ReadFile(schemaCopy)
+	if err != nil {
+		t.Fatalf("schema file not passed: %v", err)
+	}
+
+	// Codex's strict mode needs every property required, with optional
+	// ones nullable, and closed objects.
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decoding schema: %v", err)
+	}
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"verdict": map[string]any{"type": "string", "enum": []any{"yes", "no"}},
+			"issues":  map[string]any{"type": []any{"array", "null"}, "items": map[string]any{"type": "string"}},
+			"score":   map[string]any{"type": []any{"number", "null"}},
+		},
+		"required":             []any{"issues", "score", "verdict"},
+		"additionalProperties": false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schema = %s", data)
+	}
+}
+
+func TestRunOutputSchemaNotStrict(t *testing.T) {
+	type answer struct {
+		Counts map[string]int `json:"counts"`
+	}
+	c := NewClient(WithExecutable("true"))
+	_, err := c.Run(t.Context(), "hello", belaykit.WithOutputSchema(schema.For[answer]()))
+	if !errors.Is(err, schema.ErrNotStrict) || !strings.Contains(err.Error(), "$.counts") {
+		t.Fatalf("err = %v, want ErrNotStrict naming $.counts", err)
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	var events []belaykit.Event
+	c := NewClient(WithReplay("testdata/tool_run.jsonl"), WithDefaultModel("gpt-5-codex"))
+	res, err := c.Run(t.Context(), "why does TestParse pass?", belaykit.WithEventHandler(func(e belaykit.Event) {
+		events = append(events, e)
+	}))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if res.Text != "The test is empty, so it cannot fail." || res.SessionID != "0199a6f2-3c4d-7e10-9b2a-5f8e1d0c4b31" {
+		t.Errorf("result = %+v", res)
+	}
+	if res.InputTokens != 9120 || res.OutputTokens != 412 || len(res.ToolCalls) != 1 {
+		t.Errorf("tokens = %d/%d, tool calls = %+v", res.InputTokens, res.OutputTokens, res.ToolCalls)
+	}
+	if len(events) == 0 || events[0].Type != belaykit.EventSystem || events[len(events)-1].Type != belaykit.EventResult {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestRunRecordThenReplay(t *testing.T) {
+	exe := writeScript(t, "codex-record.sh", `#!/bin/sh
+echo '{"type":"thread.started","thread_id":"thread-123"}'
+echo 'not json'
+echo '{"level":"warn","msg":"on stderr"}' >&2
+echo '{"type":"turn.started"}'
+echo '{"type":"item.completed","item":{"id":"item_1","type":"agent_message","text":"recorded"}}'
+echo '{"type":"turn.completed","usage":{"input_tokens":10,"output_tokens":2}}'
+`)
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+	f, err := os.Create(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, err := NewClient(WithExecutable(exe)).Run(t.Context(), "hello", belaykit.WithRecorder(f))
+	f.Close()
+	if err != nil {
+		t.Fatalf("live Run error: %v", err)
+	}
+
+	data, _ := os.ReadFile(cassette)
+	if bytes.Contains(data, []byte("not json")) || bytes.Contains(data, []byte("on stderr")) || bytes.Count(data, []byte("\n")) != 4 {
+		t.Errorf("cassette = %q, want the 4 JSON lines from stdout", data)
+	}
+	if live.Text != "recorded" {
+		t.Errorf("live Text = %q, want agent_message text", live.Text)
+	}
+
+	replayed, err := NewClient(WithReplay(cassette)).Run(t.Context(), "hello")
+	if err != nil {
+		t.Fatalf("replay Run error: %v", err)
+	}
+	live.DurationMS, replayed.DurationMS = 0, 0
+	live.RunID, replayed.RunID = "", "" // each run has its own ID
+	if !reflect.DeepEqual(live, replayed) {
+		t.Errorf("replayed = %+v\nlive = %+v", replayed, live)
+	}
+}
@@ -0,0 +1,27 @@
+package codex
+
+import (
+	"fmt"
+	"testing"
+
+	"belaykit"
+	"belaykit/belaykittest"
+)
+
+func TestConformance(t *testing.T) {
+	belaykittest.RunConformance(t, func(t *testing.T, s belaykittest.Scenario) belaykit.Agent {
+		script := fmt.Sprintf("#!/bin/sh\ntrap 'exit 130' INT\necho '{\"type\":\"thread.started\",\"thread_id\":%q}'\necho '{\"type\":\"turn.started\"}'\n", s.SessionID)
+		switch s.Behavior {
+		case belaykittest.Reply:
+			script += fmt.Sprintf(`echo '{"type":"item.completed","item":{"id":"item_0","type":"agent_message","text":%q}}'
+echo '{"type":"turn.completed","usage":{"input_tokens":10,"output_tokens":5}}'
+`, s.Text)
+		case belaykittest.Hang:
+			script += "while true; do sleep 0.05; done\n"
+		case belaykittest.Fail:
+			script += "echo 'stream error: 429 Too Many Requests' >&2\nexit 1\n"
+		}
+		exe := writeScript(t, "codex-conformance.sh", script)
+		return NewClient(WithExecutable(exe), WithObservability(s.Observability))
+	})
+}
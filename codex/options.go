@@ -33,3 +33,13 @@ func WithObservability(provider belaykit.ObservabilityProvider) ClientOption {
 		c.observability = provider
 	}
 }
+
+// WithReplay makes the client read JSONL events from a cassette file instead
+// of running the CLI. The cassette is parsed exactly like live output, so a
+// transcript recorded with belaykit.WithRecorder reproduces the original
+// run's events and Result.
+func WithReplay(path string) ClientOption {
+	return func(c *Client) {
+		c.replayPath = path
+	}
+}
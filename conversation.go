@@ -0,0 +1,76 @@
+package belaykit
+
+import (
+	"context"
+	"sync"
+)
+
+// Turn is one exchange in a Conversation.
+type Turn struct {
+	Prompt string
+	Result Result
+	Err    error
+}
+
+// Conversation holds a multi-turn session with an agent. Each Send resumes
+// the session established by the previous turn, so follow-up prompts do not
+// need to repeat earlier context.
+//
+// A Conversation is safe for concurrent use, but turns are sent one at a
+// time.
+type Conversation struct {
+	agent Agent
+	opts  []RunOption
+
+	mu        sync.Mutex
+	sessionID string
+	turns     []Turn
+}
+
+// NewConversation starts a conversation with agent. opts are applied to
+// every turn, before any per-turn options passed to Send.
+func NewConversation(agent Agent, opts ...RunOption) *Conversation {
+	return &Conversation{agent: agent, opts: opts}
+}
+
+// ResumeConversation continues an existing session with agent.
+func ResumeConversation(agent Agent, sessionID string, opts ...RunOption) *Conversation {
+	return &Conversation{agent: agent, opts: opts, sessionID: sessionID}
+}
+
+// Send runs prompt as the next turn of the conversation and records it in
+// the transcript. The session ID is updated from the result, even when the
+// turn fails, so a later Send can pick up where the session left off.
+func (c *Conversation) Send(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	runOpts := make([]RunOption, 0, len(c.opts)+len(opts)+1)
+	runOpts = append(runOpts, c.opts...)
+	runOpts = append(runOpts, opts...)
+	if c.sessionID != "" {
+		runOpts = append(runOpts, WithResumeSession(c.sessionID))
+	}
+
+	res, err := c.agent.Run(ctx, prompt, runOpts...)
+	if res.SessionID != "" {
+		c.sessionID = res.SessionID
+	}
+	c.turns = append(c.turns, Turn{Prompt: prompt, Result: res, Err: err})
+	return res, err
+}
+
+// SessionID returns the session the next turn will resume, or "" before the
+// first turn has established one.
+func (c *Conversation) SessionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionID
+}
+
+// Transcript returns a copy of the turns sent so far.
+func (c *Conversation) Transcript() []Turn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Turn(nil), c.turns...)
+}
@@ -0,0 +1,64 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConversationResumesSession(t *testing.T) {
+	var resumed []string
+	agent := AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+		cfg := NewRunConfig(opts...)
+		resumed = append(resumed, cfg.ResumeSessionID)
+		if cfg.Model != "sonnet" {
+			t.Errorf("model = %q, want conversation default", cfg.Model)
+		}
+		return Result{Text: "re: " + prompt, SessionID: "sess-1"}, nil
+	})
+
+	conv := NewConversation(agent, WithModel("sonnet"))
+	if conv.SessionID() != "" {
+		t.Errorf("SessionID = %q before first turn, want empty", conv.SessionID())
+	}
+	if _, err := conv.Send(t.Context(), "first"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := conv.Send(t.Context(), "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Text != "re: second" {
+		t.Errorf("Text = %q", res.Text)
+	}
+	if len(resumed) != 2 || resumed[0] != "" || resumed[1] != "sess-1" {
+		t.Errorf("resumed sessions = %q, want [\"\" sess-1]", resumed)
+	}
+	if conv.SessionID() != "sess-1" {
+		t.Errorf("SessionID = %q, want %q", conv.SessionID(), "sess-1")
+	}
+
+	turns := conv.Transcript()
+	if len(turns) != 2 || turns[0].Prompt != "first" || turns[1].Result.Text != "re: second" {
+		t.Errorf("transcript = %+v", turns)
+	}
+}
+
+func TestConversationRecordsFailedTurns(t *testing.T) {
+	boom := errors.New("boom")
+	agent := AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+		return Result{SessionID: "sess-2"}, boom
+	})
+
+	conv := ResumeConversation(agent, "sess-1")
+	if _, err := conv.Send(t.Context(), "hi"); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	if conv.SessionID() != "sess-2" {
+		t.Errorf("SessionID = %q, want %q", conv.SessionID(), "sess-2")
+	}
+	if turns := conv.Transcript(); len(turns) != 1 || !errors.Is(turns[0].Err, boom) {
+		t.Errorf("transcript = %+v", turns)
+	}
+}
@@ -1,6 +1,70 @@
 package belaykit
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"strings"
+)
 
 // ErrNoJSON indicates no JSON object or array was found in the response.
 var ErrNoJSON = errors.New("no JSON found in response")
+
+// ErrCLINotFound indicates an agent's CLI binary was not found on PATH.
+// Each provider's own ErrCLINotFound wraps it, so callers can match either.
+var ErrCLINotFound = errors.New("CLI not found")
+
+// ErrInterrupted indicates a run was stopped before the CLI finished, for
+// example because its context was cancelled. The error returned alongside
+// the partial Result wraps both ErrInterrupted and the cause.
+var ErrInterrupted = errors.New("run interrupted")
+
+// ErrNoAgents indicates a composite agent was constructed without any agents.
+var ErrNoAgents = errors.New("no agents configured")
+
+// CLIError is implemented by provider errors that wrap a failed CLI process.
+// It exposes the captured stderr so failures can be classified without
+// importing provider packages.
+type CLIError interface {
+	error
+	CLIStderr() string
+}
+
+// transientPatterns are lowercase stderr fragments that indicate a failure
+// caused by load on the provider rather than by the request itself.
+var transientPatterns = []string{
+	"rate limit",
+	"rate_limit",
+	"too many requests",
+	"overloaded",
+	"service unavailable",
+}
+
+// IsTransient reports whether text describes a rate-limit or overload failure.
+func IsTransient(text string) bool {
+	text = strings.ToLower(text)
+	for _, p := range transientPatterns {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable reports whether a failed run is worth attempting again, on the
+// same agent or another one. Missing CLIs, rate-limit or overload exits,
+// stalled runs, and runs that ended with an error result are retryable.
+// Context cancellation and deadline errors and guardrail violations never
+// are.
+func IsRetryable(res Result, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrGuardrail) {
+		return false
+	}
+	if errors.Is(err, ErrCLINotFound) || errors.Is(err, ErrStalled) {
+		return true
+	}
+	var cliErr CLIError
+	if errors.As(err, &cliErr) && IsTransient(cliErr.CLIStderr()) {
+		return true
+	}
+	return res.IsError
+}
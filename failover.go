@@ -0,0 +1,99 @@
+package belaykit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Verify FailoverAgent implements Agent.
+var _ Agent = (*FailoverAgent)(nil)
+
+// FailoverAgent runs a prompt against a list of agents in order, moving to
+// the next agent only when the current one fails in a retryable way (see
+// IsRetryable). The first successful result is returned.
+type FailoverAgent struct {
+	agents []Agent
+}
+
+// Failover returns an Agent that tries each of agents in order.
+func Failover(agents ...Agent) *FailoverAgent {
+	return &FailoverAgent{agents: agents}
+}
+
+// EnforcesOutputSchema reports whether every agent does (see
+// SchemaEnforcer), since any of them may serve the run.
+func (f *FailoverAgent) EnforcesOutputSchema() bool {
+	for _, agent := range f.agents {
+		if !enforcesOutputSchema(agent) {
+			return false
+		}
+	}
+	return len(f.agents) > 0
+}
+
+// Run tries each agent in turn. Before every attempt an EventAttempt event
+// naming the provider is sent where that agent sends its events: the
+// per-run event handler or else the agent's default (see
+// DefaultEventHandler), and the hooks. If every agent fails, the last
+// result and error are returned.
+func (f *FailoverAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	if len(f.agents) == 0 {
+		return Result{}, ErrNoAgents
+	}
+
+	cfg := NewRunConfig(opts...)
+
+	var res Result
+	var err error
+	var reason string
+	for i, agent := range f.agents {
+		if handler := cfg.ResolveEventHandler(DefaultEventHandler(agent)); handler != nil {
+			handler(Event{
+				Type:     EventAttempt,
+				Attempt:  i + 1,
+				Provider: AgentName(agent),
+				Text:     reason,
+				Time:     time.Now(),
+			})
+		}
+
+		res, err = agent.Run(ctx, prompt, opts...)
+		if err == nil && !res.IsError {
+			return res, nil
+		}
+		if ctx.Err() != nil || !IsRetryable(res, err) {
+			return res, err
+		}
+		reason = failureReason(res, err)
+	}
+	return res, err
+}
+
+// DefaultEventHandler returns the default event handler of an agent that
+// reports one with a DefaultEventHandler() EventHandler method, as the
+// claude and codex clients do, or nil. Wrappers resolve their own events
+// against it so that they reach the same handler as the run's events.
+func DefaultEventHandler(a Agent) EventHandler {
+	if d, ok := a.(interface{ DefaultEventHandler() EventHandler }); ok {
+		return d.DefaultEventHandler()
+	}
+	return nil
+}
+
+// AgentName returns a short provider name for an agent. Agents may implement
+// Name() string to control it; otherwise the Go type name is used.
+func AgentName(a Agent) string {
+	if n, ok := a.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", a)
+}
+
+// failureReason describes why an attempt failed, for the next attempt event.
+func failureReason(res Result, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return res.Text
+}
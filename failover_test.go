@@ -0,0 +1,170 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stubAgent returns a fixed result and error and counts its calls.
+type stubAgent struct {
+	name  string
+	res   Result
+	err   error
+	calls int
+}
+
+func (a *stubAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	a.calls++
+	return a.res, a.err
+}
+
+func (a *stubAgent) Name() string { return a.name }
+
+// stubCLIError mimics a provider ExitError.
+type stubCLIError struct{ stderr string }
+
+func (e *stubCLIError) Error() string     { return "exited: " + e.stderr }
+func (e *stubCLIError) CLIStderr() string { return e.stderr }
+
+func TestFailoverMovesOnRetryableErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		first *stubAgent
+	}{
+		{"cli not found", &stubAgent{name: "a", err: ErrCLINotFound}},
+		{"rate limited", &stubAgent{name: "a", err: &stubCLIError{"API Error: 429 rate_limit_error"}}},
+		{"overloaded", &stubAgent{name: "a", err: &stubCLIError{"Overloaded"}}},
+		{"result error", &stubAgent{name: "a", res: Result{Text: "boom", IsError: true}}},
+		{"stalled", &stubAgent{name: "a", err: fmt.Errorf("%w: %w", ErrInterrupted, &StallError{Timeout: time.Minute})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			second := &stubAgent{name: "b", res: Result{Text: "ok"}}
+			var attempts []Event
+			handler := func(e Event) {
+				if e.Type == EventAttempt {
+					attempts = append(attempts, e)
+				}
+			}
+
+			res, err := Failover(tt.first, second).Run(t.Context(), "hi", WithEventHandler(handler))
+			if err != nil {
+				t.Fatalf("Run error: %v", err)
+			}
+			if res.Text != "ok" {
+				t.Errorf("Text = %q, want %q", res.Text, "ok")
+			}
+			if len(attempts) != 2 {
+				t.Fatalf("attempt events = %d, want 2", len(attempts))
+			}
+			if attempts[0].Provider != "a" || attempts[0].Attempt != 1 {
+				t.Errorf("first attempt = %+v", attempts[0])
+			}
+			if attempts[1].Provider != "b" || attempts[1].Attempt != 2 || attempts[1].Text == "" {
+				t.Errorf("second attempt = %+v", attempts[1])
+			}
+		})
+	}
+}
+
+func TestFailoverStopsOnNonRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"canceled", context.Canceled},
+		{"generic exit", &stubCLIError{"unknown flag --foo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &stubAgent{name: "a", err: tt.err}
+			second := &stubAgent{name: "b", res: Result{Text: "ok"}}
+
+			_, err := Failover(first, second).Run(t.Context(), "hi")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if second.calls != 0 {
+				t.Errorf("second agent called %d times, want 0", second.calls)
+			}
+		})
+	}
+}
+
+func TestFailoverExhausted(t *testing.T) {
+	first := &stubAgent{name: "a", err: ErrCLINotFound}
+	second := &stubAgent{name: "b", err: &stubCLIError{"overloaded"}}
+
+	_, err := Failover(first, second).Run(t.Context(), "hi")
+	var cliErr CLIError
+	if !errors.As(err, &cliErr) {
+		t.Fatalf("expected last agent's error, got %v", err)
+	}
+}
+
+func TestFailoverNoAgents(t *testing.T) {
+	_, err := Failover().Run(t.Context(), "hi")
+	if !errors.Is(err, ErrNoAgents) {
+		t.Fatalf("err = %v, want ErrNoAgents", err)
+	}
+}
+
+func TestAgentName(t *testing.T) {
+	if got := AgentName(&stubAgent{name: "claude"}); got != "claude" {
+		t.Errorf("AgentName = %q, want %q", got, "claude")
+	}
+	if got := AgentName(Failover()); got != "*belaykit.FailoverAgent" {
+		t.Errorf("AgentName = %q, want type name", got)
+	}
+}
+
+// defaultedAgent is a stubAgent with a client-level default event handler.
+type defaultedAgent struct {
+	stubAgent
+	handler EventHandler
+}
+
+func (a *defaultedAgent) DefaultEventHandler() EventHandler { return a.handler }
+
+func TestAttemptEventsReachDefaultHandler(t *testing.T) {
+	var primary, backup []int
+	a := &defaultedAgent{
+		stubAgent: stubAgent{name: "primary", err: &stubCLIError{stderr: "overloaded_error"}},
+		handler:   func(e Event) { primary = append(primary, e.Attempt) },
+	}
+	b := &defaultedAgent{
+		stubAgent: stubAgent{name: "backup", res: Result{Text: "ok"}},
+		handler:   func(e Event) { backup = append(backup, e.Attempt) },
+	}
+
+	if _, err := Failover(a, b).Run(t.Context(), "go"); err != nil {
+		t.Fatalf("Failover error: %v", err)
+	}
+	if len(primary) != 1 || primary[0] != 1 || len(backup) != 1 || backup[0] != 2 {
+		t.Errorf("attempts seen by primary %v and backup %v, want [1] and [2]", primary, backup)
+	}
+
+	primary = nil
+	r := Retry(Cache(a, t.TempDir()), RetryMaxAttempts(2), RetryBackoff(0, 0))
+	if DefaultEventHandler(r) == nil {
+		t.Fatal("Retry and Cache do not report the wrapped agent's default handler")
+	}
+	r.Run(t.Context(), "go")
+	if len(primary) != 2 {
+		t.Errorf("default handler saw attempts %v, want 2", primary)
+	}
+
+	// A per-run handler replaces the default, as it does for the run's own
+	// events.
+	primary = nil
+	var perRun int
+	Retry(a, RetryMaxAttempts(1)).Run(t.Context(), "go", WithEventHandler(func(Event) { perRun++ }))
+	if perRun != 1 || len(primary) != 0 {
+		t.Errorf("per-run handler saw %d events, default %d; want 1 and 0", perRun, len(primary))
+	}
+}
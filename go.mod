@@ -4,4 +4,4 @@ go 1.25.5
 
 require github.com/hev/freeplay-go v0.1.0
 
-require github.com/google/uuid v1.6.0 // indirect
+require github.com/google/uuid v1.6.0
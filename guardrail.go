@@ -0,0 +1,224 @@
+package belaykit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrGuardrail indicates a run was stopped because a tool call broke a
+// guardrail. Errors returned for such runs match it with errors.Is and carry
+// a *GuardrailError naming the rule.
+var ErrGuardrail = errors.New("guardrail violated")
+
+// GuardrailError reports the tool call that broke a guardrail.
+type GuardrailError struct {
+	Rule     string
+	ToolName string
+	ToolID   string
+	Input    json.RawMessage
+}
+
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("guardrail %q blocked tool %s (%s)", e.Rule, e.ToolName, e.ToolID)
+}
+
+// Is reports whether target is ErrGuardrail.
+func (e *GuardrailError) Is(target error) bool {
+	return target == ErrGuardrail
+}
+
+// InputMatcher reports whether a value from a tool call's input breaks a
+// rule.
+type InputMatcher func(value string) bool
+
+// GuardRule matches tool calls that must not run. A tool_use event breaks
+// the rule when its tool is one of Tools and every field in Input matches.
+type GuardRule struct {
+	// Name identifies the rule in violation events and errors.
+	Name string
+
+	// Tools are the tool names the rule applies to, as reported in
+	// Event.ToolName. Names may be path.Match patterns such as
+	// "mcp__github__*". An empty list applies to every tool.
+	Tools []string
+
+	// Input maps fields of the tool's JSON input to matchers. Keys are
+	// dot-separated paths; "*" selects every element of an array or object,
+	// so "changes.*.path" matches any change's path. A field matches when
+	// any selected value does; strings are matched as is and other values
+	// as JSON. A nil matcher only requires the field to be present.
+	Input map[string]InputMatcher
+}
+
+// Matches reports whether e is a tool call that breaks the rule.
+func (r GuardRule) Matches(e Event) bool {
+	if e.Type != EventToolUse || !r.matchesTool(e.ToolName) {
+		return false
+	}
+	if len(r.Input) == 0 {
+		return true
+	}
+
+	var input any
+	if err := json.Unmarshal(e.ToolInput, &input); err != nil {
+		return false
+	}
+	for field, match := range r.Input {
+		values := lookupField(input, strings.Split(field, "."))
+		if !matchesAny(values, match) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r GuardRule) matchesTool(name string) bool {
+	if len(r.Tools) == 0 {
+		return true
+	}
+	for _, pattern := range r.Tools {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupField returns the values at a field path in decoded JSON.
+func lookupField(v any, keys []string) []any {
+	if len(keys) == 0 {
+		return []any{v}
+	}
+	key, rest := keys[0], keys[1:]
+
+	var values []any
+	switch v := v.(type) {
+	case map[string]any:
+		if key == "*" {
+			for _, child := range v {
+				values = append(values, lookupField(child, rest)...)
+			}
+		} else if child, ok := v[key]; ok {
+			values = lookupField(child, rest)
+		}
+	case []any:
+		if key == "*" {
+			for _, child := range v {
+				values = append(values, lookupField(child, rest)...)
+			}
+		} else if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(v) {
+			values = lookupField(v[i], rest)
+		}
+	}
+	return values
+}
+
+func matchesAny(values []any, match InputMatcher) bool {
+	for _, v := range values {
+		if match == nil {
+			return true
+		}
+		s, ok := v.(string)
+		if !ok {
+			raw, _ := json.Marshal(v)
+			s = string(raw)
+		}
+		if match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRegexp returns an InputMatcher for values matching the regular
+// expression expr. It panics if expr does not compile, like
+// regexp.MustCompile, so rules can be declared as package variables.
+func MatchRegexp(expr string) InputMatcher {
+	re := regexp.MustCompile(expr)
+	return re.MatchString
+}
+
+// OutsideDir returns an InputMatcher for file paths outside dir. Relative
+// paths are resolved against dir. Symbolic links are not followed.
+func OutsideDir(dir string) InputMatcher {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		root = filepath.Clean(dir)
+	}
+	return func(p string) bool {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(root, p)
+		}
+		rel, err := filepath.Rel(root, filepath.Clean(p))
+		return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+}
+
+// CommandRule returns a rule blocking shell commands that match the regular
+// expression expr, run through Claude's Bash tool or a codex
+// command_execution item:
+//
+//	belaykit.CommandRule("force-push", `git\s+push\s+.*(--force|-f\b)`)
+func CommandRule(name, expr string) GuardRule {
+	return GuardRule{
+		Name:  name,
+		Tools: []string{"Bash", "command_execution"},
+		Input: map[string]InputMatcher{"command": MatchRegexp(expr)},
+	}
+}
+
+// PathRules returns rules blocking the built-in file tools of Claude and
+// codex when a path they touch matches. Combine it with OutsideDir to keep
+// an agent inside its repository:
+//
+//	belaykit.WithGuardrails(belaykit.PathRules("outside-repo", belaykit.OutsideDir(repo))...)
+func PathRules(name string, match InputMatcher) []GuardRule {
+	rule := func(field string, tools ...string) GuardRule {
+		return GuardRule{Name: name, Tools: tools, Input: map[string]InputMatcher{field: match}}
+	}
+	return []GuardRule{
+		rule("file_path", "Read", "Write", "Edit", "MultiEdit"),
+		rule("notebook_path", "NotebookEdit"),
+		rule("path", "Glob", "Grep", "LS"),
+		rule("changes.*.path", "file_change"),
+	}
+}
+
+// checkGuardrails returns the EventGuardrail event for a tool call that
+// breaks one of the run's guardrails, and aborts the run. Only the first
+// violation is reported.
+func (t *RunTracker) checkGuardrails(e Event) []Event {
+	if e.Type != EventToolUse || len(t.cfg.Guardrails) == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.violated {
+		return nil
+	}
+	for _, rule := range t.cfg.Guardrails {
+		if !rule.Matches(e) {
+			continue
+		}
+		t.violated = true
+		err := &GuardrailError{Rule: rule.Name, ToolName: e.ToolName, ToolID: e.ToolID, Input: e.ToolInput}
+		t.abortLocked(err)
+		return []Event{{
+			Type:      EventGuardrail,
+			Subtype:   rule.Name,
+			Text:      err.Error(),
+			ToolName:  e.ToolName,
+			ToolID:    e.ToolID,
+			ToolInput: e.ToolInput,
+			IsError:   true,
+		}}
+	}
+	return nil
+}
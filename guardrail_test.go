@@ -0,0 +1,122 @@
+package belaykit
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func toolUse(name, input string) Event {
+	return Event{Type: EventToolUse, ToolID: "tool-1", ToolName: name, ToolInput: json.RawMessage(input)}
+}
+
+func TestGuardRuleMatches(t *testing.T) {
+	repo := t.TempDir()
+	outside := PathRules("outside-repo", OutsideDir(repo))
+
+	tests := []struct {
+		name  string
+		rules []GuardRule
+		event Event
+		want  bool
+	}{
+		{name: "rm -rf", rules: []GuardRule{CommandRule("rm", `rm\s+-rf`)}, event: toolUse("Bash", `{"command":"rm -rf /"}`), want: true},
+		{name: "safe command", rules: []GuardRule{CommandRule("rm", `rm\s+-rf`)}, event: toolUse("Bash", `{"command":"ls"}`)},
+		{name: "codex command", rules: []GuardRule{CommandRule("force-push", `git push .*--force`)}, event: toolUse("command_execution", `{"type":"command_execution","command":"git push origin --force"}`), want: true},
+		{name: "other tool", rules: []GuardRule{CommandRule("rm", `rm`)}, event: toolUse("Read", `{"command":"rm"}`)},
+		{name: "tool result", rules: []GuardRule{{Name: "any"}}, event: Event{Type: EventToolResult, ToolName: "Bash"}},
+		{name: "any tool", rules: []GuardRule{{Name: "any"}}, event: toolUse("Read", `{}`), want: true},
+		{name: "tool glob", rules: []GuardRule{{Name: "github", Tools: []string{"mcp__github__*"}}}, event: toolUse("mcp__github__merge_pr", `{}`), want: true},
+		{name: "all fields must match", rules: []GuardRule{{Name: "both", Input: map[string]InputMatcher{"a": MatchRegexp("x"), "b": MatchRegexp("y")}}}, event: toolUse("T", `{"a":"x","b":"z"}`)},
+		{name: "missing field", rules: []GuardRule{{Name: "present", Input: map[string]InputMatcher{"a": nil}}}, event: toolUse("T", `{"b":1}`)},
+		{name: "present field", rules: []GuardRule{{Name: "present", Input: map[string]InputMatcher{"a": nil}}}, event: toolUse("T", `{"a":1}`), want: true},
+		{name: "non-string as JSON", rules: []GuardRule{{Name: "timeout", Input: map[string]InputMatcher{"timeout": MatchRegexp(`^600000$`)}}}, event: toolUse("Bash", `{"timeout":600000}`), want: true},
+		{name: "nested index", rules: []GuardRule{{Name: "first", Input: map[string]InputMatcher{"edits.0.old": MatchRegexp("secret")}}}, event: toolUse("MultiEdit", `{"edits":[{"old":"secret"}]}`), want: true},
+		{name: "invalid input", rules: []GuardRule{CommandRule("rm", `rm`)}, event: toolUse("Bash", `not json`)},
+		{name: "path inside repo", rules: outside, event: toolUse("Edit", `{"file_path":"`+filepath.Join(repo, "main.go")+`"}`)},
+		{name: "relative path inside repo", rules: outside, event: toolUse("Read", `{"file_path":"pkg/../main.go"}`)},
+		{name: "path outside repo", rules: outside, event: toolUse("Write", `{"file_path":"/etc/passwd"}`), want: true},
+		{name: "relative path escaping repo", rules: outside, event: toolUse("Glob", `{"path":"../other"}`), want: true},
+		{name: "codex change outside repo", rules: outside, event: toolUse("file_change", `{"changes":[{"path":"`+filepath.Join(repo, "a.go")+`"},{"path":"/tmp/b.go"}]}`), want: true},
+		{name: "codex change inside repo", rules: outside, event: toolUse("file_change", `{"changes":[{"path":"`+filepath.Join(repo, "a.go")+`"}]}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bool
+			for _, r := range tt.rules {
+				got = got || r.Matches(tt.event)
+			}
+			if got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutsideDir(t *testing.T) {
+	outside := OutsideDir("/repo")
+	for p, want := range map[string]bool{
+		"/repo":            false,
+		"/repo/a/b.go":     false,
+		"a/../b.go":        false,
+		"/repository/a.go": true,
+		"/repo/../etc":     true,
+		"..":               true,
+		"/":                true,
+	} {
+		if got := outside(p); got != want {
+			t.Errorf("OutsideDir(/repo)(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestRunTrackerGuardrailAborts(t *testing.T) {
+	var events []Event
+	cfg := NewRunConfig(
+		WithGuardrails(CommandRule("no-rm", `rm\s+-rf`)),
+		WithGuardrails(CommandRule("no-force-push", `push\s+--force`)),
+	)
+	ctx, tr := BeginRun(t.Context(), cfg, "", func(e Event) { events = append(events, e) })
+	defer tr.End()
+
+	tr.Emit(toolUse("Bash", `{"command":"ls"}`))
+	if ctx.Err() != nil {
+		t.Fatal("run cancelled by a safe command")
+	}
+
+	tr.Emit(toolUse("Bash", `{"command":"git push --force"}`))
+	if ctx.Err() == nil {
+		t.Fatal("expected run context to be cancelled")
+	}
+	tr.Emit(toolUse("Bash", `{"command":"rm -rf /"}`))
+
+	err := tr.InterruptError()
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, ErrGuardrail) {
+		t.Fatalf("InterruptError = %v, want interrupted guardrail error", err)
+	}
+	var gerr *GuardrailError
+	if !errors.As(err, &gerr) || gerr.Rule != "no-force-push" || gerr.ToolName != "Bash" || gerr.ToolID != "tool-1" {
+		t.Errorf("GuardrailError = %+v", gerr)
+	}
+	if IsRetryable(Result{IsError: true}, err) {
+		t.Error("guardrail violations should not be retryable")
+	}
+
+	var types []EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	want := []EventType{EventToolUse, EventToolUse, EventGuardrail, EventToolUse}
+	if len(types) != len(want) {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("event types = %v, want %v", types, want)
+		}
+	}
+	if v := events[2]; v.Subtype != "no-force-push" || !v.IsError || v.ToolName != "Bash" || string(v.ToolInput) != `{"command":"git push --force"}` {
+		t.Errorf("guardrail event = %+v", v)
+	}
+}
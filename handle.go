@@ -0,0 +1,204 @@
+package belaykit
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Starter is implemented by agents that can run in the background. The
+// claude and codex clients implement it; StartRun starts any Agent the same
+// way.
+type Starter interface {
+	Start(ctx context.Context, prompt string, opts ...RunOption) *RunHandle
+}
+
+// RunStats is a snapshot of a run in progress.
+type RunStats struct {
+	RunID     string
+	SessionID string
+	Model     string
+	Events    int           // Events emitted so far
+	ToolCalls int           // Tool calls started so far
+	CostUSD   float64       // Reported cost, or an estimate while the run is in progress (see WithModelPricing)
+	Elapsed   time.Duration // Time since the run started, or its total duration once done
+	Done      bool
+}
+
+// RunHandle controls a run started in the background. It is safe for
+// concurrent use.
+type RunHandle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	start  time.Time
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signalled when pending grows or the run finishes
+	tracker  *RunTracker
+	count    int     // events observed
+	pending  []Event // events not yet sent on the Events channel
+	watched  bool    // Events has been called
+	runID    string
+	finished bool
+	elapsed  time.Duration
+	res      Result
+	err      error
+
+	eventsOnce sync.Once
+	events     chan Event
+}
+
+// watchEvents makes StartRun keep events for Events from the start of the
+// run. RunStream sets it so it sees every event.
+func watchEvents(watch bool) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.watchEvents = watch
+	}
+}
+
+// StartRun runs agent in a new goroutine and returns a handle to it. The
+// run's events still reach its handler and hooks; the handle observes them
+// through an extra hook.
+func StartRun(ctx context.Context, agent Agent, prompt string, opts ...RunOption) *RunHandle {
+	ctx, cancel := context.WithCancel(ctx)
+	cfg := NewRunConfig(opts...)
+	tracker := NewRunTracker(cfg.Model)
+	tracker.cfg = cfg
+	h := &RunHandle{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		start:   time.Now(),
+		tracker: tracker,
+		watched: cfg.watchEvents,
+	}
+	h.cond = sync.NewCond(&h.mu)
+
+	// The agent's own runs, if it starts any, are not watched.
+	opts = append(slices.Clip(opts), WithEventHook(h.observe), watchEvents(false))
+	go func() {
+		res, err := agent.Run(ctx, prompt, opts...)
+		cancel()
+
+		h.mu.Lock()
+		h.res, h.err = res, err
+		if h.runID == "" {
+			h.runID = res.RunID
+		}
+		h.elapsed = time.Since(h.start)
+		h.finished = true
+		h.cond.Broadcast()
+		h.mu.Unlock()
+		close(h.done)
+	}()
+	return h
+}
+
+// observe records an event of the run. The tracker sees it first, so Stats
+// is never behind the Events channel.
+func (h *RunHandle) observe(e Event) {
+	h.tracker.Observe(e)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.runID == "" {
+		h.runID = e.RunID
+	}
+	h.count++
+	if h.watched {
+		h.pending = append(h.pending, e)
+		h.cond.Broadcast()
+	}
+}
+
+// Events returns a channel carrying the run's events from the first call on,
+// which is closed once the run is over. Events emitted before the first call
+// are not delivered; use RunStream to see every event. Events are held until
+// they are read, so a caller that calls Events must drain the channel. A
+// handle whose Events is never called keeps no events.
+func (h *RunHandle) Events() <-chan Event {
+	h.eventsOnce.Do(func() {
+		h.mu.Lock()
+		h.watched = true
+		h.mu.Unlock()
+		h.events = make(chan Event)
+		go h.pump()
+	})
+	return h.events
+}
+
+// pump sends pending events on the Events channel, forgetting each once it
+// has been sent.
+func (h *RunHandle) pump() {
+	for {
+		h.mu.Lock()
+		for len(h.pending) == 0 && !h.finished {
+			h.cond.Wait()
+		}
+		if len(h.pending) == 0 {
+			h.mu.Unlock()
+			close(h.events)
+			return
+		}
+		e := h.pending[0]
+		h.pending[0] = Event{}
+		h.pending = h.pending[1:]
+		h.mu.Unlock()
+		h.events <- e
+	}
+}
+
+// Done returns a channel that is closed when the run is over.
+func (h *RunHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the run is over and returns its Result and error.
+func (h *RunHandle) Wait() (Result, error) {
+	<-h.done
+	return h.res, h.err
+}
+
+// Interrupt stops the run as if its context had been cancelled: the agent
+// gets its grace period to finish, and Wait returns the partial Result and
+// an error wrapping ErrInterrupted. It does not wait for the run to stop.
+func (h *RunHandle) Interrupt() {
+	h.cancel()
+}
+
+// SessionID returns the agent session ID, once the run has reported it.
+func (h *RunHandle) SessionID() string {
+	return h.Stats().SessionID
+}
+
+// Stats returns a snapshot of the run so far.
+func (h *RunHandle) Stats() RunStats {
+	res := h.tracker.Result()
+	cost := h.tracker.LiveCost()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := RunStats{
+		RunID:     h.runID,
+		SessionID: res.SessionID,
+		Model:     res.Model,
+		Events:    h.count,
+		ToolCalls: len(res.ToolCalls),
+		CostUSD:   cost,
+		Elapsed:   time.Since(h.start),
+		Done:      h.finished,
+	}
+	if h.finished {
+		s.Elapsed = h.elapsed
+		if h.res.SessionID != "" {
+			s.SessionID = h.res.SessionID
+		}
+		if h.res.Model != "" {
+			s.Model = h.res.Model
+		}
+		if h.res.CostUSD != 0 {
+			s.CostUSD = h.res.CostUSD
+		}
+	}
+	return s
+}
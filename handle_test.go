@@ -0,0 +1,127 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// gatedAgent waits for begin, if set, emits a session init and a tool call,
+// then waits for release or cancellation before finishing.
+type gatedAgent struct {
+	begin   chan struct{}
+	release chan struct{}
+}
+
+func (a *gatedAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	cfg := NewRunConfig(opts...)
+	ctx, tracker := BeginRun(ctx, cfg, "", cfg.ResolveEventHandler(nil))
+	defer tracker.End()
+
+	if a.begin != nil {
+		<-a.begin
+	}
+
+	tracker.Emit(Event{Type: EventSystem, Subtype: "init", SessionID: "sess-1", Model: "opus"})
+	tracker.Emit(Event{Type: EventToolUse, ToolID: "t1", ToolName: "Bash"})
+	select {
+	case <-a.release:
+	case <-ctx.Done():
+		return tracker.Result(), tracker.InterruptError()
+	}
+	tracker.Emit(Event{Type: EventToolResult, ToolID: "t1", Text: "ok"})
+	tracker.Emit(Event{Type: EventResult, Text: "done", CostUSD: 0.25})
+	return tracker.Result(), nil
+}
+
+func TestStartRun(t *testing.T) {
+	agent := &gatedAgent{begin: make(chan struct{}), release: make(chan struct{})}
+	var handled int
+	h := StartRun(t.Context(), agent, "go", WithEventHandler(func(Event) { handled++ }))
+
+	events := h.Events()
+	close(agent.begin)
+	for range 2 {
+		<-events
+	}
+	stats := h.Stats()
+	if stats.Done || stats.SessionID != "sess-1" || stats.Model != "opus" || stats.ToolCalls != 1 || stats.Events != 2 {
+		t.Errorf("live Stats = %+v", stats)
+	}
+	if stats.RunID == "" {
+		t.Error("live Stats has no RunID")
+	}
+	if h.SessionID() != "sess-1" {
+		t.Errorf("SessionID = %q, want sess-1", h.SessionID())
+	}
+
+	close(agent.release)
+	var rest []EventType
+	for e := range events {
+		rest = append(rest, e.Type)
+	}
+	if len(rest) != 2 || rest[1] != EventResult {
+		t.Errorf("remaining events = %v, want tool_result and result", rest)
+	}
+
+	res, err := h.Wait()
+	if err != nil || res.Text != "done" {
+		t.Fatalf("Wait = %+v, %v", res, err)
+	}
+	if handled != 4 {
+		t.Errorf("run handler saw %d events, want 4", handled)
+	}
+	stats = h.Stats()
+	if !stats.Done || stats.CostUSD != 0.25 || stats.RunID != res.RunID || stats.Events != 4 {
+		t.Errorf("final Stats = %+v", stats)
+	}
+}
+
+func TestStartRunInterrupt(t *testing.T) {
+	agent := &gatedAgent{begin: make(chan struct{})}
+	h := StartRun(t.Context(), agent, "go")
+	events := h.Events()
+	close(agent.begin)
+	for e := range events {
+		if e.Type == EventToolUse {
+			h.Interrupt()
+		}
+	}
+	<-h.Done()
+
+	res, err := h.Wait()
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want interrupted by cancellation", err)
+	}
+	if res.SessionID != "sess-1" {
+		t.Errorf("partial Result = %+v", res)
+	}
+}
+
+func TestStartRunKeepsEventsOnlyWhenWatched(t *testing.T) {
+	agent := &gatedAgent{release: make(chan struct{})}
+	h := StartRun(t.Context(), agent, "go")
+	for h.Stats().Events < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	h.mu.Lock()
+	kept := len(h.pending)
+	h.mu.Unlock()
+	if kept != 0 {
+		t.Errorf("unwatched handle kept %d events", kept)
+	}
+
+	events := h.Events()
+	close(agent.release)
+	var got []EventType
+	for e := range events {
+		got = append(got, e.Type)
+	}
+	if len(got) != 2 || got[0] != EventToolResult || got[1] != EventResult {
+		t.Errorf("events = %v, want only those after Events was called", got)
+	}
+	if h.Stats().Events != 4 {
+		t.Errorf("Stats.Events = %d, want 4", h.Stats().Events)
+	}
+}
@@ -0,0 +1,117 @@
+package belaykit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// panicOutput receives reports of handler panics recovered by MultiHandler.
+var panicOutput io.Writer = os.Stderr
+
+// MultiHandler returns a handler that passes every event to each of
+// handlers, in order. A handler that panics is reported on stderr and
+// skipped for that event; the other handlers and the run carry on. Nil
+// handlers are ignored.
+//
+//	claude.WithDefaultEventHandler(belaykit.MultiHandler(
+//	    belaykit.NewLogger(os.Stderr),
+//	    bp.EventHandler(),
+//	    belaykit.Filter(slackH, belaykit.OnlyTypes(belaykit.EventResult, belaykit.EventResultError)),
+//	))
+func MultiHandler(handlers ...EventHandler) EventHandler {
+	handlers = slices.DeleteFunc(slices.Clone(handlers), func(h EventHandler) bool { return h == nil })
+	return func(e Event) {
+		for _, h := range handlers {
+			callIsolated(h, e)
+		}
+	}
+}
+
+func callIsolated(h EventHandler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(panicOutput, "belaykit: event handler panicked on %s event: %v\n%s", e.Type, r, debug.Stack())
+		}
+	}()
+	h(e)
+}
+
+// EventFilter reports whether an event should be passed on. Filters may keep
+// state, so create a new one for each handler.
+type EventFilter func(Event) bool
+
+// Filter returns a handler that passes an event to h only if every filter
+// accepts it. Filters are consulted in order and stop at the first that
+// rejects the event.
+func Filter(h EventHandler, filters ...EventFilter) EventHandler {
+	return func(e Event) {
+		for _, f := range filters {
+			if !f(e) {
+				return
+			}
+		}
+		h(e)
+	}
+}
+
+// OnlyTypes accepts events of the given types.
+func OnlyTypes(types ...EventType) EventFilter {
+	return func(e Event) bool {
+		return slices.Contains(types, e.Type)
+	}
+}
+
+// ExceptTypes rejects events of the given types.
+func ExceptTypes(types ...EventType) EventFilter {
+	return func(e Event) bool {
+		return !slices.Contains(types, e.Type)
+	}
+}
+
+// ExceptTools rejects tool_use events for the named tools and the
+// tool_result events that answer them.
+func ExceptTools(names ...string) EventFilter {
+	var mu sync.Mutex
+	skipped := make(map[string]bool) // tool IDs of rejected tool_use events
+	return func(e Event) bool {
+		switch e.Type {
+		case EventToolUse:
+			if !slices.Contains(names, e.ToolName) {
+				return true
+			}
+			mu.Lock()
+			skipped[e.ToolID] = true
+			mu.Unlock()
+			return false
+		case EventToolResult:
+			mu.Lock()
+			defer mu.Unlock()
+			if skipped[e.ToolID] {
+				delete(skipped, e.ToolID)
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Sample accepts one event in every n, starting with the first. Combine it
+// with OnlyTypes to thin out a single kind of event:
+//
+//	belaykit.Filter(metrics, belaykit.OnlyTypes(belaykit.EventAssistant), belaykit.Sample(10))
+//
+// An n below 2 accepts every event.
+func Sample(n int) EventFilter {
+	var seen atomic.Uint64
+	return func(Event) bool {
+		if n < 2 {
+			return true
+		}
+		return (seen.Add(1)-1)%uint64(n) == 0
+	}
+}
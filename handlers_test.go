@@ -0,0 +1,79 @@
+package belaykit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMultiHandlerIsolatesPanics(t *testing.T) {
+	var out bytes.Buffer
+	saved := panicOutput
+	panicOutput = &out
+	t.Cleanup(func() { panicOutput = saved })
+
+	var first, last []EventType
+	h := MultiHandler(
+		func(e Event) { first = append(first, e.Type) },
+		nil,
+		func(e Event) {
+			if e.Type == EventToolUse {
+				panic("boom")
+			}
+		},
+		func(e Event) { last = append(last, e.Type) },
+	)
+	h(Event{Type: EventSystem})
+	h(Event{Type: EventToolUse})
+	h(Event{Type: EventResult})
+
+	want := []EventType{EventSystem, EventToolUse, EventResult}
+	for _, got := range [][]EventType{first, last} {
+		if len(got) != len(want) || got[1] != EventToolUse {
+			t.Errorf("handler saw %v, want %v", got, want)
+		}
+	}
+	if !strings.Contains(out.String(), "event handler panicked on tool_use event: boom") {
+		t.Errorf("panic report = %q", out.String())
+	}
+}
+
+func TestFilters(t *testing.T) {
+	events := []Event{
+		{Type: EventSystem, Subtype: "init"},
+		{Type: EventToolUse, ToolID: "t1", ToolName: "Read"},
+		{Type: EventToolResult, ToolID: "t1"},
+		{Type: EventToolUse, ToolID: "t2", ToolName: "Bash"},
+		{Type: EventToolResult, ToolID: "t2"},
+		{Type: EventAssistant, Text: "a"},
+		{Type: EventAssistant, Text: "b"},
+		{Type: EventAssistant, Text: "c"},
+		{Type: EventResult},
+	}
+
+	tests := []struct {
+		name    string
+		filters []EventFilter
+		want    int // indexes into events, as a bitmask
+	}{
+		{"only types", []EventFilter{OnlyTypes(EventSystem, EventResult)}, 1<<0 | 1<<8},
+		{"except types", []EventFilter{ExceptTypes(EventAssistant, EventToolUse, EventToolResult)}, 1<<0 | 1<<8},
+		{"except tools", []EventFilter{ExceptTools("Read")}, 1<<0 | 1<<3 | 1<<4 | 1<<5 | 1<<6 | 1<<7 | 1<<8},
+		{"sample", []EventFilter{Sample(4)}, 1<<0 | 1<<4 | 1<<8},
+		{"sample after type filter", []EventFilter{OnlyTypes(EventAssistant), Sample(2)}, 1<<5 | 1<<7},
+		{"sample of one", []EventFilter{Sample(1), OnlyTypes(EventAssistant)}, 1<<5 | 1<<6 | 1<<7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			i := 0
+			h := Filter(func(Event) { got |= 1 << i }, tt.filters...)
+			for ; i < len(events); i++ {
+				h(events[i])
+			}
+			if got != tt.want {
+				t.Errorf("passed events %b, want %b", got, tt.want)
+			}
+		})
+	}
+}
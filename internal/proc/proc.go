@@ -0,0 +1,77 @@
+// Package proc supervises agent CLI processes on behalf of the provider
+// packages. Instead of killing the CLI outright when a run is cancelled, it
+// interrupts the whole process group, gives the CLI a grace period to flush
+// its final output, and only then kills it.
+package proc
+
+import (
+	"context"
+	"os/exec"
+	"sync/atomic"
+	"time"
+)
+
+// Process is a started CLI process under supervision.
+type Process struct {
+	cmd         *exec.Cmd
+	done        chan struct{}
+	interrupted atomic.Bool
+	succeeded   atomic.Bool // exited with status 0
+}
+
+// Start starts cmd in its own process group and watches ctx. When ctx is
+// done the group receives SIGINT (os.Interrupt where process groups are not
+// supported); if it is still running after grace it is killed.
+//
+// cmd must not have been created with exec.CommandContext, since that would
+// kill the process without a grace period.
+func Start(ctx context.Context, cmd *exec.Cmd, grace time.Duration) (*Process, error) {
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &Process{cmd: cmd, done: make(chan struct{})}
+	go p.watch(ctx, grace)
+	return p, nil
+}
+
+func (p *Process) watch(ctx context.Context, grace time.Duration) {
+	select {
+	case <-p.done:
+		return
+	case <-ctx.Done():
+	}
+
+	p.interrupted.Store(true)
+	if err := interruptGroup(p.cmd); err != nil {
+		killGroup(p.cmd)
+		return
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+	case <-timer.C:
+		killGroup(p.cmd)
+	}
+}
+
+// Wait waits for the process to exit and stops supervision. As with
+// exec.Cmd.Wait, all reads from the process's pipes must finish first.
+func (p *Process) Wait() error {
+	err := p.cmd.Wait()
+	p.succeeded.Store(p.cmd.ProcessState != nil && p.cmd.ProcessState.Success())
+	close(p.done)
+	return err
+}
+
+// Interrupted reports whether the process was interrupted because its
+// context was done. Supervision lasts until Wait, so the context can end
+// after the process has already exited, while its output is still being
+// read; a process that exited successfully is never reported as
+// interrupted.
+func (p *Process) Interrupted() bool {
+	return p.interrupted.Load() && !p.succeeded.Load()
+}
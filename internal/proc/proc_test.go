@@ -0,0 +1,116 @@
+//go:build unix
+
+package proc
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterruptWithinGrace(t *testing.T) {
+	cmd := exec.Command("sh", "-c", `trap 'echo flushed; exit 130' INT; echo ready; while true; do sleep 0.05; done`)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	p, err := Start(ctx, cmd, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if scanner.Text() == "ready" {
+			cancel()
+		}
+	}
+	p.Wait()
+
+	if !p.Interrupted() {
+		t.Error("expected Interrupted to be true")
+	}
+	if strings.Join(lines, ",") != "ready,flushed" {
+		t.Errorf("output = %v, want output flushed after SIGINT", lines)
+	}
+}
+
+func TestKillAfterGrace(t *testing.T) {
+	cmd := exec.Command("sh", "-c", `trap '' INT; echo ready; while true; do sleep 0.05; done`)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	p, err := Start(ctx, cmd, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		cancel()
+	}
+	if err := p.Wait(); err == nil {
+		t.Error("expected error from killed process")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("process took %v to die, want kill after grace", elapsed)
+	}
+}
+
+func TestNoInterruptOnNormalExit(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "exit 0")
+	p, err := Start(t.Context(), cmd, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Interrupted() {
+		t.Error("expected Interrupted to be false")
+	}
+}
+
+func TestNoInterruptAfterExit(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "echo done")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	p, err := Start(ctx, cmd, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The context ends after the CLI has exited but before Wait, while the
+	// caller is still draining its output.
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Interrupted() {
+		t.Error("expected Interrupted to be false for a process that exited on its own")
+	}
+}
@@ -35,6 +35,10 @@ type loggerConfig struct {
 	toolUse       bool
 	toolResult    bool
 	result        bool
+	attempt       bool
+	budget        bool
+	guardrail     bool
+	approval      bool
 	tokens        bool
 	content       bool
 	contextWindow int
@@ -69,6 +73,29 @@ func LogResult(on bool) LoggerOption {
 	return func(cfg *loggerConfig) { cfg.result = on }
 }
 
+// LogAttempt toggles logging of attempt events from composite agents such
+// as Failover.
+func LogAttempt(on bool) LoggerOption {
+	return func(cfg *loggerConfig) { cfg.attempt = on }
+}
+
+// LogBudget toggles logging of budget events, which show a run's spend and
+// remaining headroom against its cost limits.
+func LogBudget(on bool) LoggerOption {
+	return func(cfg *loggerConfig) { cfg.budget = on }
+}
+
+// LogGuardrail toggles logging of guardrail violations (see WithGuardrails).
+func LogGuardrail(on bool) LoggerOption {
+	return func(cfg *loggerConfig) { cfg.guardrail = on }
+}
+
+// LogApproval toggles logging of tool approval decisions (see
+// claude.WithToolApprover).
+func LogApproval(on bool) LoggerOption {
+	return func(cfg *loggerConfig) { cfg.approval = on }
+}
+
 // LogTokens enables estimated token usage and context window tracking on each
 // log line. Use WithContextWindow to set the context window size; otherwise
 // the default of 200,000 tokens is used.
@@ -125,6 +152,10 @@ func NewLogger(w io.Writer, opts ...LoggerOption) EventHandler {
 		toolUse:       true,
 		toolResult:    true,
 		result:        true,
+		attempt:       true,
+		budget:        true,
+		guardrail:     true,
+		approval:      true,
 		tokens:        true,
 		content:       true,
 		contextWindow: 200_000,
@@ -143,27 +174,39 @@ func NewLogger(w io.Writer, opts ...LoggerOption) EventHandler {
 	assistantPrefix := buildTagPrefix("assistant", cfg.modelName, cfg.agentName)
 
 	var mu sync.Mutex
-	sessionStart := now()
-	var inputTokens, outputTokens int
-	var inTurn bool
+	sessions := map[string]*logSession{"": {start: now()}}
 
 	return func(e Event) {
 		mu.Lock()
 		defer mu.Unlock()
 
+		// Events tagged with a job ID (see RunAll) are tracked and labelled
+		// separately so interleaved runs stay readable.
+		s := sessions[e.JobID]
+		if s == nil {
+			s = &logSession{start: now()}
+			sessions[e.JobID] = s
+		}
+		if e.JobID != "" && (e.Type == EventResult || e.Type == EventResultError) {
+			delete(sessions, e.JobID)
+		}
+		write := func(line string) {
+			if e.JobID != "" {
+				line = fmt.Sprintf("%s[job:%s]%s %s", colorDim, e.JobID, colorReset, line)
+			}
+			w.Write([]byte(line))
+		}
+
 		// Reset per-session counters when a new session starts.
 		if e.Type == EventSystem && e.Subtype == "init" {
-			inputTokens = 0
-			outputTokens = 0
-			sessionStart = now()
-			inTurn = false
+			*s = logSession{start: now()}
 		}
 
 		// Always count tokens when tracking is enabled.
 		if cfg.tokens {
 			in, out := classifyEventTokens(e)
-			inputTokens += in
-			outputTokens += out
+			s.inputTokens += in
+			s.outputTokens += out
 		}
 
 		switch e.Type {
@@ -174,93 +217,141 @@ func NewLogger(w io.Writer, opts ...LoggerOption) EventHandler {
 			if !cfg.assistant {
 				return
 			}
-			inTurn = true
+			s.inTurn = true
 			line := fmt.Sprintf("%s%s%s", colorGreen, assistantPrefix, colorReset)
 			if cfg.tokens {
-				line += "  " + formatThermobar(inputTokens+outputTokens, cfg.contextWindow)
-				line += "  " + formatMetrics(cfg, inputTokens, outputTokens, now().Sub(sessionStart))
+				line += "  " + formatThermobar(s.inputTokens+s.outputTokens, cfg.contextWindow)
+				line += "  " + formatMetrics(cfg, s.inputTokens, s.outputTokens, now().Sub(s.start))
 			}
-			w.Write([]byte(line + "\n"))
+			write(line + "\n")
 
 		case EventAssistant:
 			if !cfg.assistant {
 				return
 			}
-			if !inTurn {
-				inTurn = true
+			if !s.inTurn {
+				s.inTurn = true
 				header := fmt.Sprintf("%s%s%s", colorGreen, assistantPrefix, colorReset)
 				if cfg.tokens {
-					header += "  " + formatThermobar(inputTokens+outputTokens, cfg.contextWindow)
-					header += "  " + formatMetrics(cfg, inputTokens, outputTokens, now().Sub(sessionStart))
+					header += "  " + formatThermobar(s.inputTokens+s.outputTokens, cfg.contextWindow)
+					header += "  " + formatMetrics(cfg, s.inputTokens, s.outputTokens, now().Sub(s.start))
 				}
-				w.Write([]byte(header + "\n"))
+				write(header + "\n")
 			}
 			if cfg.content {
-				w.Write([]byte("  " + e.Text + "\n"))
+				write("  " + e.Text + "\n")
 			}
 
 		case EventToolUse:
 			if !cfg.toolUse {
 				return
 			}
-			if !inTurn {
+			if !s.inTurn {
 				if cfg.assistant {
-					inTurn = true
+					s.inTurn = true
 					header := fmt.Sprintf("%s%s%s", colorGreen, assistantPrefix, colorReset)
 					if cfg.tokens {
-						header += "  " + formatThermobar(inputTokens+outputTokens, cfg.contextWindow)
-						header += "  " + formatMetrics(cfg, inputTokens, outputTokens, now().Sub(sessionStart))
+						header += "  " + formatThermobar(s.inputTokens+s.outputTokens, cfg.contextWindow)
+						header += "  " + formatMetrics(cfg, s.inputTokens, s.outputTokens, now().Sub(s.start))
 					}
-					w.Write([]byte(header + "\n"))
+					write(header + "\n")
 				}
 			}
 			indent := "  "
-			if !inTurn {
+			if !s.inTurn {
 				indent = ""
 			}
 			body := " " + e.ToolName
 			if cfg.content && len(e.ToolInput) > 0 {
 				body += " " + truncate(string(e.ToolInput), maxToolInputLen)
 			}
-			w.Write([]byte(fmt.Sprintf("%s%s[tool_use]%s%s\n", indent, colorCyan, colorReset, body)))
+			write(fmt.Sprintf("%s%s[tool_use]%s%s\n", indent, colorCyan, colorReset, body))
 
 		case EventToolResult:
 			if !cfg.toolResult {
 				return
 			}
 			indent := "  "
-			if !inTurn {
+			if !s.inTurn {
 				indent = ""
 			}
 			var body string
 			if cfg.content {
 				body = " " + truncate(e.Text, maxToolResultLen)
 			}
-			w.Write([]byte(fmt.Sprintf("%s%s[tool_result]%s%s\n", indent, colorBlue, colorReset, body)))
+			write(fmt.Sprintf("%s%s[tool_result]%s%s\n", indent, colorBlue, colorReset, body))
 
 		case EventResult:
 			if !cfg.result {
 				return
 			}
-			inTurn = false
+			s.inTurn = false
 			body := fmt.Sprintf(" turns=%d duration=%dms", e.NumTurns, e.Duration)
 			line := fmt.Sprintf("%s[result]%s%s", colorMagenta, colorReset, body)
 			if cfg.tokens {
-				line += "  " + formatThermobar(inputTokens+outputTokens, cfg.contextWindow)
-				line += "  " + formatMetrics(cfg, inputTokens, outputTokens, now().Sub(sessionStart))
+				line += "  " + formatThermobar(s.inputTokens+s.outputTokens, cfg.contextWindow)
+				line += "  " + formatMetrics(cfg, s.inputTokens, s.outputTokens, now().Sub(s.start))
 			}
-			w.Write([]byte(line + "\n"))
+			write(line + "\n")
 
 		case EventResultError:
 			if !cfg.result {
 				return
 			}
-			inTurn = false
+			s.inTurn = false
 			var body string
 			if cfg.content {
 				body = " " + e.Text
 			}
-			w.Write([]byte(fmt.Sprintf("%s[error]%s%s\n", colorBoldRed, colorReset, body)))
+			write(fmt.Sprintf("%s[error]%s%s\n", colorBoldRed, colorReset, body))
+
+		case EventAttempt:
+			if !cfg.attempt {
+				return
+			}
+			body := fmt.Sprintf(" #%d %s", e.Attempt, e.Provider)
+			if cfg.content && e.Text != "" {
+				body += " (after: " + truncate(e.Text, maxToolResultLen) + ")"
+			}
+			write(fmt.Sprintf("%s[attempt]%s%s\n", colorYellow, colorReset, body))
+
+		case EventBudget:
+			if !cfg.budget {
+				return
+			}
+			color := colorYellow
+			if e.IsError {
+				color = colorBoldRed
+			}
+			body := fmt.Sprintf(" %s $%.4f of $%.4f ($%.4f left)",
+				e.Subtype, e.BudgetSpentUSD, e.BudgetLimitUSD, e.BudgetLimitUSD-e.BudgetSpentUSD)
+			write(fmt.Sprintf("%s[budget]%s%s\n", color, colorReset, body))
+
+		case EventGuardrail:
+			if !cfg.guardrail {
+				return
+			}
+			body := fmt.Sprintf(" %s blocked %s", e.Subtype, e.ToolName)
+			if cfg.content && len(e.ToolInput) > 0 {
+				body += ": " + truncate(string(e.ToolInput), maxToolResultLen)
+			}
+			write(fmt.Sprintf("%s[guardrail]%s%s\n", colorBoldRed, colorReset, body))
+
+		case EventApproval:
+			if !cfg.approval {
+				return
+			}
+			color := colorGreen
+			body := fmt.Sprintf(" %s %s", e.Subtype, e.ToolName)
+			if e.Subtype == ApprovalDeny {
+				color = colorBoldRed
+				if cfg.content && e.Text != "" {
+					body += ": " + e.Text
+				}
+			} else if cfg.content && len(e.ToolInput) > 0 {
+				body += ": " + truncate(string(e.ToolInput), maxToolResultLen)
+			}
+			write(fmt.Sprintf("%s[approval]%s%s\n", color, colorReset, body))
 
 		default:
 			return
@@ -268,6 +359,14 @@ func NewLogger(w io.Writer, opts ...LoggerOption) EventHandler {
 	}
 }
 
+// logSession is the logger's per-session state.
+type logSession struct {
+	start        time.Time
+	inputTokens  int
+	outputTokens int
+	inTurn       bool
+}
+
 // buildTagPrefix builds a bracket-delimited tag with optional model and agent suffixes.
 // e.g. buildTagPrefix("assistant", "opus", "researcher") => "[assistant:opus:researcher]"
 func buildTagPrefix(tag, model, agent string) string {
@@ -347,7 +446,7 @@ func classifyEventTokens(e Event) (input, output int) {
 	case EventSystem:
 		// System prompt / init overhead
 		return EstimateTokens(e.Subtype) + EstimateTokens(e.SessionID), 0
-	case EventAssistantStart:
+	case EventAssistantStart, EventAttempt, EventBudget, EventGuardrail, EventApproval:
 		return 0, 0
 	default:
 		return EstimateTokens(e.Text), 0
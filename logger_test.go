@@ -167,6 +167,43 @@ func TestLoggerToolUseFormat(t *testing.T) {
 	}
 }
 
+func TestLoggerGuardrailFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger(Event{Type: EventGuardrail, Subtype: "no-rm", ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"rm -rf /"}`), IsError: true})
+	output := buf.String()
+	for _, want := range []string{"[guardrail]", "no-rm blocked Bash", `{"command":"rm -rf /"}`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in %q", want, output)
+		}
+	}
+
+	buf.Reset()
+	NewLogger(&buf, LogGuardrail(false))(Event{Type: EventGuardrail, Subtype: "no-rm"})
+	if buf.Len() != 0 {
+		t.Errorf("expected no output with LogGuardrail(false), got %q", buf.String())
+	}
+}
+
+func TestLoggerApprovalFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger(Event{Type: EventApproval, Subtype: ApprovalAllow, ToolName: "Read", ToolInput: json.RawMessage(`{"file_path":"a.go"}`)})
+	logger(Event{Type: EventApproval, Subtype: ApprovalDeny, ToolName: "Bash", Text: "no shell"})
+	output := buf.String()
+	for _, want := range []string{"[approval]", `allow Read: {"file_path":"a.go"}`, "deny Bash: no shell"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in %q", want, output)
+		}
+	}
+
+	buf.Reset()
+	NewLogger(&buf, LogApproval(false))(Event{Type: EventApproval, Subtype: ApprovalAllow})
+	if buf.Len() != 0 {
+		t.Errorf("expected no output with LogApproval(false), got %q", buf.String())
+	}
+}
+
 func TestLoggerResultFormat(t *testing.T) {
 	var buf bytes.Buffer
 	logger := NewLogger(&buf)
@@ -943,3 +980,57 @@ func TestBuildTagPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggerAttemptFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger(Event{Type: EventAttempt, Attempt: 2, Provider: "codex", Text: "claude CLI not found"})
+	output := buf.String()
+	for _, want := range []string{"[attempt]", "#2 codex", "claude CLI not found"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in %q", want, output)
+		}
+	}
+
+	buf.Reset()
+	logger = NewLogger(&buf, LogAttempt(false))
+	logger(Event{Type: EventAttempt, Attempt: 1, Provider: "claude"})
+	if buf.Len() != 0 {
+		t.Errorf("expected no output when attempt disabled, got %q", buf.String())
+	}
+}
+
+func TestLoggerBudgetFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger(Event{Type: EventBudget, Subtype: BudgetScopeShared, BudgetSpentUSD: 0.25, BudgetLimitUSD: 1})
+	output := buf.String()
+	for _, want := range []string{"[budget]", "shared $0.2500 of $1.0000", "$0.7500 left"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in %q", want, output)
+		}
+	}
+}
+
+func TestLoggerJobTagsAndSeparateTurns(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, LogTokens(false))
+
+	logger(Event{Type: EventAssistant, Text: "job a text", JobID: "a"})
+	logger(Event{Type: EventAssistant, Text: "job b text", JobID: "b"})
+	logger(Event{Type: EventToolUse, ToolName: "Read", JobID: "a"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{"[job:a]", "[job:a]", "[job:b]", "[job:b]", "[job:a]"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d (a header per job): %q", len(lines), len(want), lines)
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, want[i]) {
+			t.Errorf("line %d = %q, want tag %s", i, line, want[i])
+		}
+	}
+	if !strings.Contains(lines[4], "  "+colorCyan+"[tool_use]") {
+		t.Errorf("job a tool_use should be indented under its own turn: %q", lines[4])
+	}
+}
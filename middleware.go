@@ -0,0 +1,78 @@
+package belaykit
+
+import (
+	"context"
+	"time"
+)
+
+// AgentFunc adapts an ordinary function to the Agent interface.
+type AgentFunc func(ctx context.Context, prompt string, opts ...RunOption) (Result, error)
+
+// Run calls f(ctx, prompt, opts...).
+func (f AgentFunc) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	return f(ctx, prompt, opts...)
+}
+
+// Middleware wraps an Agent with additional behavior.
+type Middleware func(Agent) Agent
+
+// Chain wraps agent with mws. The first middleware is the outermost: it sees
+// the prompt and options first and the result last.
+func Chain(agent Agent, mws ...Middleware) Agent {
+	for i := len(mws) - 1; i >= 0; i-- {
+		agent = mws[i](agent)
+	}
+	return agent
+}
+
+// RewritePrompt returns a Middleware that replaces the prompt with fn(prompt).
+func RewritePrompt(fn func(prompt string) string) Middleware {
+	return func(next Agent) Agent {
+		return AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+			return next.Run(ctx, fn(prompt), opts...)
+		})
+	}
+}
+
+// RewriteConfig returns a Middleware that resolves the run options, lets fn
+// modify the resulting RunConfig, and passes it down the chain.
+func RewriteConfig(fn func(cfg *RunConfig)) Middleware {
+	return func(next Agent) Agent {
+		return AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+			cfg := NewRunConfig(opts...)
+			fn(&cfg)
+			return next.Run(ctx, prompt, WithRunConfig(cfg))
+		})
+	}
+}
+
+// RewriteResult returns a Middleware that passes every result and error
+// through fn before returning them.
+func RewriteResult(fn func(res Result, err error) (Result, error)) Middleware {
+	return func(next Agent) Agent {
+		return AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+			return fn(next.Run(ctx, prompt, opts...))
+		})
+	}
+}
+
+// TapEvents returns a Middleware that sends every event of the run to h,
+// alongside the caller's handler and the client default.
+func TapEvents(h EventHandler) Middleware {
+	return func(next Agent) Agent {
+		return AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+			return next.Run(ctx, prompt, append(opts[:len(opts):len(opts)], WithEventHook(h))...)
+		})
+	}
+}
+
+// Timeout returns a Middleware that bounds each run to d.
+func Timeout(d time.Duration) Middleware {
+	return func(next Agent) Agent {
+		return AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next.Run(ctx, prompt, opts...)
+		})
+	}
+}
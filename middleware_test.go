@@ -0,0 +1,114 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingAgent captures the prompt and resolved config of its last run
+// and emits a single result event.
+type recordingAgent struct {
+	prompt   string
+	cfg      RunConfig
+	deadline bool
+	fallback EventHandler
+}
+
+func (a *recordingAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	a.prompt = prompt
+	a.cfg = NewRunConfig(opts...)
+	_, a.deadline = ctx.Deadline()
+	if h := a.cfg.ResolveEventHandler(a.fallback); h != nil {
+		h(Event{Type: EventResult, Text: "done"})
+	}
+	return Result{Text: "done"}, nil
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next Agent) Agent {
+			return AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+				order = append(order, name)
+				return next.Run(ctx, prompt, opts...)
+			})
+		}
+	}
+
+	agent := &recordingAgent{}
+	if _, err := Chain(agent, mw("outer"), mw("inner")).Run(t.Context(), "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("order = %v, want [outer inner]", order)
+	}
+}
+
+func TestBuiltinMiddlewares(t *testing.T) {
+	agent := &recordingAgent{}
+	chained := Chain(agent,
+		RewritePrompt(func(p string) string { return p + "\nBe brief." }),
+		RewriteConfig(func(cfg *RunConfig) { cfg.Model = "haiku" }),
+		RewriteResult(func(res Result, err error) (Result, error) {
+			res.Text = strings.ToUpper(res.Text)
+			return res, err
+		}),
+		Timeout(time.Minute),
+	)
+
+	res, err := chained.Run(t.Context(), "hi", WithModel("opus"), WithSystemPrompt("sys"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.prompt != "hi\nBe brief." {
+		t.Errorf("prompt = %q", agent.prompt)
+	}
+	if agent.cfg.Model != "haiku" || agent.cfg.SystemPrompt != "sys" {
+		t.Errorf("cfg = %+v, want model overridden and system prompt kept", agent.cfg)
+	}
+	if res.Text != "DONE" {
+		t.Errorf("Text = %q, want %q", res.Text, "DONE")
+	}
+	if !agent.deadline {
+		t.Error("expected Timeout to set a deadline")
+	}
+}
+
+func TestTapEventsKeepsExistingHandlers(t *testing.T) {
+	var defaultCalls, callerCalls, tapCalls int
+	agent := &recordingAgent{fallback: func(Event) { defaultCalls++ }}
+	tap := TapEvents(func(Event) { tapCalls++ })
+
+	// Without a per-run handler the client default still fires.
+	if _, err := Chain(agent, tap).Run(t.Context(), "hi"); err != nil {
+		t.Fatal(err)
+	}
+	// With a per-run handler it replaces the default, but the tap still fires.
+	if _, err := Chain(agent, tap).Run(t.Context(), "hi", WithEventHandler(func(Event) { callerCalls++ })); err != nil {
+		t.Fatal(err)
+	}
+
+	if defaultCalls != 1 || callerCalls != 1 || tapCalls != 2 {
+		t.Errorf("default=%d caller=%d tap=%d, want 1/1/2", defaultCalls, callerCalls, tapCalls)
+	}
+}
+
+func TestResolveEventHandlerNil(t *testing.T) {
+	if h := NewRunConfig().ResolveEventHandler(nil); h != nil {
+		t.Error("expected nil handler when nothing is configured")
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	slow := AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+		<-ctx.Done()
+		return Result{}, ctx.Err()
+	})
+	_, err := Chain(slow, Timeout(10*time.Millisecond)).Run(t.Context(), "hi")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+}
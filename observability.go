@@ -35,7 +35,11 @@ type TraceConfig struct {
 // Fields are populated automatically from the agent result event.
 type CompletionRecord struct {
 	TraceID      string  // Trace this completion belongs to (from WithTraceID)
+	JobID        string  // Job this completion belongs to (from WithJobID), if any
+	RunID        string  // Run that produced the completion (see Result.RunID)
+	ParentRunID  string  // Run that started it (from WithParentRunID), if any
 	SessionID    string  // Agent session ID (from the system init event)
+	Provider     string  // Agent provider that served the run (e.g., "claude", "codex")
 	Prompt       string  // The input prompt
 	Response     string  // The result text
 	Model        string  // The model used (resolved from aliases)
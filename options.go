@@ -1,6 +1,17 @@
 package belaykit
 
-import "io"
+import (
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"belaykit/schema"
+)
+
+// DefaultGracePeriod is how long an interrupted CLI gets to write its final
+// output before it is killed.
+const DefaultGracePeriod = 5 * time.Second
 
 // RunConfig holds per-run configuration. Exported so sub-packages (agent
 // implementations) can read the resolved options.
@@ -12,8 +23,30 @@ type RunConfig struct {
 	DisallowedTools []string
 	OutputStream    io.Writer
 	EventHandler    EventHandler
+	EventHooks      []EventHandler
 	SystemPrompt    string
 	TraceID         string
+	ResumeSessionID string
+	WorkDir         string
+	Env             []string
+	EnvOverrides    map[string]string
+	AdditionalDirs  []string
+	GracePeriod     time.Duration
+	MaxCostUSD      float64
+	Budget          *Budget
+	Pricing         ModelPricing
+	IdleTimeout     time.Duration
+	ToolTimeout     time.Duration
+	MaxRepairs      int
+	OutputSchema    *schema.Schema
+	JobID           string
+	CacheMode       CacheMode
+	Recorder        io.Writer
+	ParentRunID     string
+	ToolPolicy      *ToolPolicy
+	Guardrails      []GuardRule
+
+	watchEvents bool // StartRun keeps events for Events from the start
 }
 
 // RunOption configures a single Run invocation.
@@ -21,7 +54,7 @@ type RunOption func(*RunConfig)
 
 // NewRunConfig resolves a set of RunOptions into a RunConfig.
 func NewRunConfig(opts ...RunOption) RunConfig {
-	var cfg RunConfig
+	cfg := RunConfig{GracePeriod: DefaultGracePeriod, MaxRepairs: DefaultMaxRepairs}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
@@ -80,6 +113,52 @@ func WithEventHandler(h EventHandler) RunOption {
 	}
 }
 
+// WithEventHook adds an event handler that receives every event in addition
+// to the run's primary handler. Unlike WithEventHandler it never replaces
+// another handler, so middlewares can observe a run without clobbering the
+// caller's handler or the client default.
+func WithEventHook(h EventHandler) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.EventHooks = append(cfg.EventHooks, h)
+	}
+}
+
+// WithRunConfig replaces the whole resolved configuration. Middlewares use
+// it to pass a modified RunConfig down the chain.
+func WithRunConfig(c RunConfig) RunOption {
+	return func(cfg *RunConfig) {
+		*cfg = c
+	}
+}
+
+// ResolveEventHandler returns the handler an agent should send events to:
+// the per-run handler if set, otherwise fallback (typically the client
+// default), followed by every hook added with WithEventHook. Events are
+// tagged with the run's JobID, if any. Returns nil when there is nothing to
+// call.
+func (cfg RunConfig) ResolveEventHandler(fallback EventHandler) EventHandler {
+	primary := fallback
+	if cfg.EventHandler != nil {
+		primary = cfg.EventHandler
+	}
+	if len(cfg.EventHooks) == 0 && (primary == nil || cfg.JobID == "") {
+		return primary
+	}
+	hooks := append([]EventHandler(nil), cfg.EventHooks...)
+	jobID := cfg.JobID
+	return func(e Event) {
+		if jobID != "" {
+			e.JobID = jobID
+		}
+		if primary != nil {
+			primary(e)
+		}
+		for _, h := range hooks {
+			h(e)
+		}
+	}
+}
+
 // WithSystemPrompt sets the system prompt for this run.
 func WithSystemPrompt(s string) RunOption {
 	return func(cfg *RunConfig) {
@@ -96,3 +175,224 @@ func WithTraceID(id string) RunOption {
 		cfg.TraceID = id
 	}
 }
+
+// WithResumeSession continues an existing agent session instead of starting
+// a new one. The session ID is reported in Result.SessionID and on the
+// system init event.
+func WithResumeSession(id string) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.ResumeSessionID = id
+	}
+}
+
+// WithWorkDir runs the agent CLI in dir instead of the current directory.
+func WithWorkDir(dir string) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.WorkDir = dir
+	}
+}
+
+// WithEnv replaces the environment of the agent CLI with env, given as
+// "KEY=value" entries. By default the CLI inherits the parent environment.
+func WithEnv(env ...string) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.Env = env
+	}
+}
+
+// WithEnvOverrides sets environment variables for the agent CLI on top of
+// the inherited environment (or the one given to WithEnv). Repeated calls
+// merge, with later values winning.
+func WithEnvOverrides(vars map[string]string) RunOption {
+	return func(cfg *RunConfig) {
+		if cfg.EnvOverrides == nil {
+			cfg.EnvOverrides = make(map[string]string, len(vars))
+		}
+		for k, v := range vars {
+			cfg.EnvOverrides[k] = v
+		}
+	}
+}
+
+// WithAdditionalDirs grants the agent access to directories outside its
+// working directory.
+func WithAdditionalDirs(dirs ...string) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.AdditionalDirs = dirs
+	}
+}
+
+// CommandEnv returns the environment for the agent CLI process: the base
+// environment (Env, or the parent's if unset), then EnvOverrides, then
+// extra "KEY=value" entries. Later entries win. Returns nil when nothing
+// differs from the parent environment, so the process simply inherits it.
+func (cfg RunConfig) CommandEnv(extra ...string) []string {
+	if cfg.Env == nil && len(cfg.EnvOverrides) == 0 && len(extra) == 0 {
+		return nil
+	}
+	env := cfg.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	env = append([]string(nil), env...)
+
+	keys := make([]string, 0, len(cfg.EnvOverrides))
+	for k := range cfg.EnvOverrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+cfg.EnvOverrides[k])
+	}
+	return append(env, extra...)
+}
+
+// WithGracePeriod sets how long the CLI may keep running after the run is
+// interrupted. On interrupt the CLI's process group receives SIGINT; output
+// is still parsed during the grace period, and the group is killed once it
+// expires. Defaults to DefaultGracePeriod.
+func WithGracePeriod(d time.Duration) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.GracePeriod = d
+	}
+}
+
+// WithMaxCostUSD interrupts the run once its cost reaches usd. Cost is taken
+// from the CLI's result event when available and estimated from streamed
+// tokens (see WithModelPricing) while the run is in progress; a final cost
+// over the limit is reported with an EventBudget but does not fail the
+// finished run.
+func WithMaxCostUSD(usd float64) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.MaxCostUSD = usd
+	}
+}
+
+// WithBudget charges the run against a Budget shared with other runs. The
+// run is refused if the budget is already exhausted, and interrupted if it
+// exhausts the budget while in progress.
+func WithBudget(b *Budget) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.Budget = b
+	}
+}
+
+// WithModelPricing sets the pricing used to estimate the cost of a run
+// before the CLI reports it. Agents that know their models' pricing (such
+// as the claude client) fill this in when it is not set.
+func WithModelPricing(p ModelPricing) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.Pricing = p
+	}
+}
+
+// CheckBudget returns a *BudgetExceededError if the run's shared budget is
+// already exhausted. Agents call it before starting a run.
+func (cfg RunConfig) CheckBudget() error {
+	if cfg.Budget == nil {
+		return nil
+	}
+	return cfg.Budget.Err()
+}
+
+// WithIdleTimeout interrupts the run if the CLI emits no events for d. The
+// run fails with a *StallError.
+func WithIdleTimeout(d time.Duration) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.IdleTimeout = d
+	}
+}
+
+// WithToolTimeout interrupts the run if a tool_use event is not followed by
+// its tool_result within d. The run fails with a *StallError naming the
+// stuck tool.
+func WithToolTimeout(d time.Duration) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.ToolTimeout = d
+	}
+}
+
+// WithMaxRepairs sets how many times RunTyped re-prompts the agent when its
+// reply cannot be decoded. Zero disables repairs. Defaults to
+// DefaultMaxRepairs.
+func WithMaxRepairs(n int) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.MaxRepairs = n
+	}
+}
+
+// WithOutputSchema asks the agent to reply with JSON matching s. Codex
+// enforces it with --output-schema; claude receives it as an appended system
+// prompt. Parse the reply with ExtractValidJSON.
+func WithOutputSchema(s *schema.Schema) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.OutputSchema = s
+	}
+}
+
+// WithJobID tags the run's events and completion record with a job ID, so
+// handlers and observability providers can tell interleaved runs apart.
+// RunAll sets it for each job.
+func WithJobID(id string) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.JobID = id
+	}
+}
+
+// WithCacheBypass runs the agent without reading or writing a CachingAgent's
+// cache.
+func WithCacheBypass() RunOption {
+	return func(cfg *RunConfig) {
+		cfg.CacheMode = CacheBypass
+	}
+}
+
+// WithCacheRefresh runs the agent even if a CachingAgent has a cached
+// result, and replaces the cached result with the new one.
+func WithCacheRefresh() RunOption {
+	return func(cfg *RunConfig) {
+		cfg.CacheMode = CacheRefresh
+	}
+}
+
+// WithRecorder tees every JSON line the CLI prints on stdout to w, one
+// object per line. The result is a cassette that claude.WithReplay and codex.WithReplay
+// can play back.
+func WithRecorder(w io.Writer) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.Recorder = w
+	}
+}
+
+// WithParentRunID records that this run was started on behalf of another
+// run, such as an orchestrator fanning out work. The ID is stamped on every
+// event and completion record of this run so the two can be stitched
+// together; take it from the parent's Result.RunID or events.
+func WithParentRunID(id string) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.ParentRunID = id
+	}
+}
+
+// WithToolPolicy restricts the run to a provider-neutral ToolPolicy. Each
+// client validates the policy before starting and translates it into its
+// CLI's permission flags, failing the run for settings it cannot express.
+// See ToolPolicy.Network for where providers enforce the same policy
+// differently.
+func WithToolPolicy(p *ToolPolicy) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.ToolPolicy = p
+	}
+}
+
+// WithGuardrails stops the run as soon as a tool call breaks one of rules.
+// The tracker emits an EventGuardrail after the offending tool_use event and
+// interrupts the CLI; Run returns an error wrapping ErrInterrupted and a
+// *GuardrailError naming the rule. The CLI may already have started the
+// tool, so guardrails complement a ToolPolicy rather than replace it.
+// Repeated calls add to the rules.
+func WithGuardrails(rules ...GuardRule) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.Guardrails = append(cfg.Guardrails, rules...)
+	}
+}
@@ -0,0 +1,36 @@
+package belaykit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommandEnvInheritsByDefault(t *testing.T) {
+	if env := NewRunConfig().CommandEnv(); env != nil {
+		t.Errorf("CommandEnv = %v, want nil to inherit the parent environment", env)
+	}
+}
+
+func TestCommandEnvLayering(t *testing.T) {
+	cfg := NewRunConfig(
+		WithEnv("PATH=/bin", "HOME=/home/a"),
+		WithEnvOverrides(map[string]string{"HOME": "/home/b"}),
+		WithEnvOverrides(map[string]string{"TOKEN": "x"}),
+	)
+	got := cfg.CommandEnv("EXTRA=1")
+	want := []string{"PATH=/bin", "HOME=/home/a", "HOME=/home/b", "TOKEN=x", "EXTRA=1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("CommandEnv = %v, want %v", got, want)
+	}
+}
+
+func TestCommandEnvOverridesParent(t *testing.T) {
+	t.Setenv("BELAYKIT_TEST_VAR", "parent")
+	env := NewRunConfig(WithEnvOverrides(map[string]string{"BELAYKIT_TEST_VAR": "child"})).CommandEnv()
+	if !slices.Contains(env, "BELAYKIT_TEST_VAR=parent") {
+		t.Error("expected parent environment to be inherited")
+	}
+	if env[len(env)-1] != "BELAYKIT_TEST_VAR=child" {
+		t.Errorf("last entry = %q, want override", env[len(env)-1])
+	}
+}
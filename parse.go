@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"belaykit/schema"
 )
 
 // StripCodeFences removes markdown code fences from a string so the
@@ -58,3 +60,25 @@ func ExtractJSONArray(s string, dst any) error {
 	}
 	return nil
 }
+
+// ExtractValidJSON is like ExtractJSON, or ExtractJSONArray when s describes
+// an array, but validates the JSON against s before unmarshalling it into
+// dst. A reply that does not match returns a *schema.ValidationError naming
+// each offending field.
+func ExtractValidJSON(text string, s *schema.Schema, dst any) error {
+	var raw json.RawMessage
+	extract := ExtractJSON
+	if s.Type == "array" {
+		extract = ExtractJSONArray
+	}
+	if err := extract(text, &raw); err != nil {
+		return err
+	}
+	if err := s.ValidateJSON(raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("parsing JSON: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package belaykit
 import (
 	"errors"
 	"testing"
+
+	"belaykit/schema"
 )
 
 func TestStripCodeFences(t *testing.T) {
@@ -183,3 +185,30 @@ func TestExtractJSONArray_ErrNoJSON(t *testing.T) {
 		t.Errorf("expected ErrNoJSON, got %v", err)
 	}
 }
+
+func TestExtractValidJSON(t *testing.T) {
+	type answer struct {
+		Verdict string `json:"verdict" enum:"yes,no"`
+		Reason  string `json:"reason"`
+	}
+	s := schema.For[answer]()
+
+	var dst answer
+	if err := ExtractValidJSON("```json\n{\"verdict\": \"yes\", \"reason\": \"ok\"}\n```", s, &dst); err != nil {
+		t.Fatalf("ExtractValidJSON error: %v", err)
+	}
+	if dst.Verdict != "yes" || dst.Reason != "ok" {
+		t.Errorf("dst = %+v", dst)
+	}
+
+	err := ExtractValidJSON(`{"verdict": "maybe"}`, s, &dst)
+	var verr *schema.ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 2 {
+		t.Fatalf("err = %v, want 2 field errors", err)
+	}
+
+	var list []answer
+	if err := ExtractValidJSON(`[{"verdict": "no", "reason": "x"}]`, schema.For[[]answer](), &list); err != nil || len(list) != 1 {
+		t.Errorf("array: list = %+v, err = %v", list, err)
+	}
+}
@@ -0,0 +1,138 @@
+package belaykit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidPolicy indicates a ToolPolicy that fails validation.
+var ErrInvalidPolicy = errors.New("invalid tool policy")
+
+// Access is the file-system access a ToolPolicy grants the agent.
+type Access string
+
+const (
+	// AccessReadOnly lets the agent read files but not change them. The
+	// claude client enforces it with plan mode, in which the agent proposes
+	// changes instead of running tools that have side effects.
+	AccessReadOnly Access = "read-only"
+	// AccessWorkspaceWrite lets the agent edit files in its working
+	// directory and any additional directories.
+	AccessWorkspaceWrite Access = "workspace-write"
+	// AccessFull lifts every restriction. Use it only in disposable
+	// environments.
+	AccessFull Access = "full"
+)
+
+// ToolPolicy describes what an agent may do, independently of the provider.
+// Each client translates it into its CLI's permission flags; see
+// WithToolPolicy. LoadToolPolicy reads one from a file, so every agent can
+// share the same policy.
+//
+// Clients reject settings they cannot enforce, but the enforcement itself
+// is the CLI's and differs between providers; see Network.
+type ToolPolicy struct {
+	// Access is the file-system access granted. Required.
+	Access Access `json:"access"`
+
+	// Network allows network access from tools. It must be true with
+	// AccessFull, which cannot restrict the network.
+	//
+	// Codex sandboxes every command, but its read-only sandbox has no
+	// network, so the codex client rejects AccessReadOnly with Network.
+	// Claude does not sandbox shell commands: with Network false it only
+	// disables its web tools, and Bash can still reach the network unless
+	// Deny covers it, e.g. with "Bash".
+	Network bool `json:"network"`
+
+	// Allow and Deny are tool patterns: a tool name such as "Read", or a
+	// name and specifier such as "Bash(git diff:*)". Deny wins over Allow.
+	// Tool names are provider-specific, so only clients that can enforce
+	// patterns accept them.
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// toolPattern matches "Name" or "Name(specifier)".
+var toolPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]+(\([^()]+\))?$`)
+
+// Validate reports every problem with the policy. The returned error wraps
+// ErrInvalidPolicy.
+func (p ToolPolicy) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidPolicy, fmt.Sprintf(format, args...)))
+	}
+
+	switch p.Access {
+	case AccessReadOnly, AccessWorkspaceWrite:
+	case AccessFull:
+		if !p.Network {
+			fail("access %q cannot disable the network", p.Access)
+		}
+	case "":
+		fail("access is required")
+	default:
+		fail("unknown access %q, want %q, %q or %q", p.Access, AccessReadOnly, AccessWorkspaceWrite, AccessFull)
+	}
+
+	for _, list := range []struct {
+		name     string
+		patterns []string
+	}{{"allow", p.Allow}, {"deny", p.Deny}} {
+		for _, pat := range list.patterns {
+			if !toolPattern.MatchString(pat) {
+				fail("%s pattern %q is not a tool name or Name(specifier)", list.name, pat)
+			}
+		}
+	}
+	for _, pat := range p.Allow {
+		if slices.Contains(p.Deny, pat) {
+			fail("pattern %q is both allowed and denied", pat)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LoadToolPolicy reads a JSON policy file from an fs.FS and validates it.
+func LoadToolPolicy(fsys fs.FS, path string) (*ToolPolicy, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy %s: %w", path, err)
+	}
+	var p ToolPolicy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parsing policy %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// String returns a one-line summary of the policy, e.g.
+// "workspace-write, no network, allow [Read Edit], deny [Bash]".
+func (p ToolPolicy) String() string {
+	parts := []string{string(p.Access)}
+	if p.Network {
+		parts = append(parts, "network")
+	} else {
+		parts = append(parts, "no network")
+	}
+	if len(p.Allow) > 0 {
+		parts = append(parts, fmt.Sprintf("allow %v", p.Allow))
+	}
+	if len(p.Deny) > 0 {
+		parts = append(parts, fmt.Sprintf("deny %v", p.Deny))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,80 @@
+package belaykit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestToolPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ToolPolicy
+		wantErr string
+	}{
+		{name: "read only", policy: ToolPolicy{Access: AccessReadOnly}},
+		{name: "patterns", policy: ToolPolicy{Access: AccessWorkspaceWrite, Allow: []string{"Read", "Bash(git diff:*)"}, Deny: []string{"mcp__github__create_pr"}}},
+		{name: "full with network", policy: ToolPolicy{Access: AccessFull, Network: true}},
+		{name: "missing access", policy: ToolPolicy{}, wantErr: "access is required"},
+		{name: "unknown access", policy: ToolPolicy{Access: "admin"}, wantErr: `unknown access "admin"`},
+		{name: "full without network", policy: ToolPolicy{Access: AccessFull}, wantErr: "cannot disable the network"},
+		{name: "bad pattern", policy: ToolPolicy{Access: AccessReadOnly, Deny: []string{"Bash(rm"}}, wantErr: `deny pattern "Bash(rm"`},
+		{name: "allowed and denied", policy: ToolPolicy{Access: AccessReadOnly, Allow: []string{"Edit"}, Deny: []string{"Edit"}}, wantErr: `"Edit" is both allowed and denied`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidPolicy) {
+				t.Fatalf("Validate() = %v, want ErrInvalidPolicy", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToolPolicyValidateReportsAllProblems(t *testing.T) {
+	err := ToolPolicy{Allow: []string{"("}}.Validate()
+	for _, want := range []string{"access is required", `allow pattern "("`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestLoadToolPolicy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"policy.json":  {Data: []byte(`{"access": "workspace-write", "network": true, "deny": ["Bash(git push:*)"]}`)},
+		"invalid.json": {Data: []byte(`{"access": "full"}`)},
+		"unknown.json": {Data: []byte(`{"access": "read-only", "sandbox": "on"}`)},
+	}
+
+	p, err := LoadToolPolicy(fsys, "policy.json")
+	if err != nil {
+		t.Fatalf("LoadToolPolicy error: %v", err)
+	}
+	if p.Access != AccessWorkspaceWrite || !p.Network || len(p.Deny) != 1 {
+		t.Errorf("policy = %+v", p)
+	}
+	if got, want := p.String(), "workspace-write, network, deny [Bash(git push:*)]"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if _, err := LoadToolPolicy(fsys, "invalid.json"); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("invalid policy error = %v, want ErrInvalidPolicy", err)
+	}
+	if _, err := LoadToolPolicy(fsys, "unknown.json"); err == nil || !strings.Contains(err.Error(), "sandbox") {
+		t.Errorf("unknown field error = %v", err)
+	}
+	if _, err := LoadToolPolicy(fsys, "missing.json"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -7,10 +7,7 @@
 //	logger := belaykit.NewLogger(os.Stderr)
 //	client := claude.NewClient(
 //	    claude.WithObservability(bp),
-//	    claude.WithDefaultEventHandler(func(e belaykit.Event) {
-//	        logger(e)
-//	        bp.EventHandler()(e)
-//	    }),
+//	    claude.WithDefaultEventHandler(belaykit.MultiHandler(logger, bp.EventHandler())),
 //	)
 //
 //	tid := bp.StartTrace(belaykit.TraceConfig{Name: "my-run"}, nil)
@@ -45,6 +42,8 @@ type traceNode struct {
 	InputTokens   int          `json:"input_tokens"`
 	OutputTokens  int          `json:"output_tokens"`
 	ContextWindow int          `json:"context_window,omitempty"`
+	RunID         string       `json:"run_id,omitempty"`
+	ParentRunID   string       `json:"parent_run_id,omitempty"`
 	Children      []*traceNode `json:"children,omitempty"`
 }
 
@@ -62,6 +61,7 @@ type Provider struct {
 	phaseStart   time.Time             // current phase start time
 	toolStart    map[string]time.Time  // toolID -> start time
 	toolNodes    map[string]*traceNode // toolID -> in-progress tool node
+	jobNodes     map[string]*traceNode // jobID -> job node in the current phase
 	traceID      string                // current trace ID
 	inputTokens  int                   // accumulated input token estimate
 	outputTokens int                   // accumulated output token estimate
@@ -97,6 +97,7 @@ func NewProvider(opts ...Option) *Provider {
 		dir:       ".belay/traces",
 		toolStart: make(map[string]time.Time),
 		toolNodes: make(map[string]*traceNode),
+		jobNodes:  make(map[string]*traceNode),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -129,6 +130,7 @@ func (p *Provider) StartTrace(config belaykit.TraceConfig, inputs map[string]any
 	p.currentPhase = nil
 	p.toolStart = make(map[string]time.Time)
 	p.toolNodes = make(map[string]*traceNode)
+	p.jobNodes = make(map[string]*traceNode)
 	p.inputTokens = 0
 	p.outputTokens = 0
 	return id
@@ -164,17 +166,18 @@ func (p *Provider) RecordCompletion(record belaykit.CompletionRecord) {
 
 	// If no phase exists, create a default one
 	if p.currentPhase == nil {
-		p.currentPhase = &traceNode{
-			ID:        shortID(),
-			NodeType:  "phase",
-			AgentName: "default",
-		}
-		p.phaseStart = time.Now().Add(-time.Duration(record.DurationMS) * time.Millisecond)
-		p.root.Children = append(p.root.Children, p.currentPhase)
+		p.startDefaultPhase(time.Now().Add(-time.Duration(record.DurationMS) * time.Millisecond))
+	}
+
+	// Completions from RunAll jobs are recorded on the job's node so
+	// interleaved runs are not merged into one.
+	node := p.currentPhase
+	if record.JobID != "" {
+		node = p.jobNode(record.JobID)
 	}
 
-	p.currentPhase.Model = record.Model
-	p.currentPhase.DurationMS += record.DurationMS
+	node.Model = record.Model
+	node.DurationMS += record.DurationMS
 
 	// Use token counts from record if available, otherwise use accumulated estimates
 	inTok := record.InputTokens
@@ -183,24 +186,21 @@ func (p *Provider) RecordCompletion(record belaykit.CompletionRecord) {
 		inTok = p.inputTokens
 		outTok = p.outputTokens
 	}
-	p.currentPhase.InputTokens += inTok
-	p.currentPhase.OutputTokens += outTok
+	node.InputTokens += inTok
+	node.OutputTokens += outTok
 
 	// Use cost from record if available, otherwise estimate from pricing
 	cost := record.CostUSD
 	if cost == 0 && (inTok > 0 || outTok > 0) {
 		cost = p.pricing.Cost(inTok, outTok)
 	}
-	p.currentPhase.CostUSD += cost
+	node.CostUSD += cost
 }
 
 // EventHandler returns an EventHandler function that captures tool-level
 // events for the trace tree. Compose it alongside the logger:
 //
-//	handler := func(e belaykit.Event) {
-//	    logger(e)
-//	    bp.EventHandler()(e)
-//	}
+//	handler := belaykit.MultiHandler(logger, bp.EventHandler())
 func (p *Provider) EventHandler() belaykit.EventHandler {
 	return func(e belaykit.Event) {
 		p.mu.Lock()
@@ -253,20 +253,48 @@ func (p *Provider) handlePhase(e belaykit.Event) {
 		NodeType:  "phase",
 		AgentName: e.PhaseName,
 	}
-	p.phaseStart = time.Now()
+	p.phaseStart = eventTime(e)
+	p.jobNodes = make(map[string]*traceNode)
+	p.root.Children = append(p.root.Children, p.currentPhase)
+}
+
+// startDefaultPhase opens a phase for events and completions that arrive
+// without an explicit phase.
+func (p *Provider) startDefaultPhase(start time.Time) {
+	p.currentPhase = &traceNode{
+		ID:        shortID(),
+		NodeType:  "phase",
+		AgentName: "default",
+	}
+	p.phaseStart = start
+	p.jobNodes = make(map[string]*traceNode)
 	p.root.Children = append(p.root.Children, p.currentPhase)
 }
 
+// jobNode returns the node grouping a job's tool calls and completion in
+// the current phase, creating it on first use.
+func (p *Provider) jobNode(jobID string) *traceNode {
+	node, ok := p.jobNodes[jobID]
+	if !ok {
+		node = &traceNode{
+			ID:        shortID(),
+			NodeType:  "job",
+			AgentName: jobID,
+		}
+		p.jobNodes[jobID] = node
+		p.currentPhase.Children = append(p.currentPhase.Children, node)
+	}
+	return node
+}
+
 func (p *Provider) handleToolUse(e belaykit.Event) {
 	if p.currentPhase == nil {
 		// Create a default phase if tools are used without an explicit phase
-		p.currentPhase = &traceNode{
-			ID:        shortID(),
-			NodeType:  "phase",
-			AgentName: "default",
-		}
-		p.phaseStart = time.Now()
-		p.root.Children = append(p.root.Children, p.currentPhase)
+		p.startDefaultPhase(eventTime(e))
+	}
+	parent := p.currentPhase
+	if e.JobID != "" {
+		parent = p.jobNode(e.JobID)
 	}
 
 	node := &traceNode{
@@ -276,10 +304,12 @@ func (p *Provider) handleToolUse(e belaykit.Event) {
 		InputTokens:   p.inputTokens,
 		OutputTokens:  p.outputTokens,
 		ContextWindow: p.contextWindow,
+		RunID:         e.RunID,
+		ParentRunID:   e.ParentRunID,
 	}
-	p.toolStart[e.ToolID] = time.Now()
+	p.toolStart[e.ToolID] = eventTime(e)
 	p.toolNodes[e.ToolID] = node
-	p.currentPhase.Children = append(p.currentPhase.Children, node)
+	parent.Children = append(parent.Children, node)
 }
 
 func (p *Provider) handleToolResult(e belaykit.Event) {
@@ -288,12 +318,21 @@ func (p *Provider) handleToolResult(e belaykit.Event) {
 		return
 	}
 	if start, ok := p.toolStart[e.ToolID]; ok {
-		node.DurationMS = time.Since(start).Milliseconds()
+		node.DurationMS = eventTime(e).Sub(start).Milliseconds()
 		delete(p.toolStart, e.ToolID)
 	}
 	delete(p.toolNodes, e.ToolID)
 }
 
+// eventTime returns when e was emitted, or the current time for events
+// that were not stamped by a run, such as phases emitted by callers.
+func eventTime(e belaykit.Event) time.Time {
+	if e.Time.IsZero() {
+		return time.Now()
+	}
+	return e.Time
+}
+
 func (p *Provider) finalizeCurrentPhase() {
 	if p.currentPhase == nil {
 		return
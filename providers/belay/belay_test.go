@@ -23,6 +23,7 @@ type traceJSON struct {
 	Model     string      `json:"model,omitempty"`
 	Duration  int64       `json:"duration_ms"`
 	CostUSD   float64     `json:"cost_usd"`
+	RunID     string      `json:"run_id,omitempty"`
 	Children  []traceJSON `json:"children,omitempty"`
 }
 
@@ -309,6 +310,33 @@ func TestToolDurationIsRecorded(t *testing.T) {
 	}
 }
 
+func TestToolDurationUsesEventTimes(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProvider(WithDir(dir))
+
+	tid := p.StartTrace(belaykit.TraceConfig{Name: "event-time-test"}, nil)
+	handler := p.EventHandler()
+
+	// Events delivered late, as by an async handler, are timed by when the
+	// run emitted them.
+	start := time.Now().Add(-time.Minute)
+	handler(belaykit.Event{Type: belaykit.EventToolUse, ToolName: "Bash", ToolID: "t1", RunID: "run-1", Seq: 1, Time: start})
+	handler(belaykit.Event{Type: belaykit.EventToolResult, ToolID: "t1", RunID: "run-1", Seq: 2, Time: start.Add(1500 * time.Millisecond)})
+	p.EndTrace(tid, nil)
+
+	data, _ := os.ReadFile(filepath.Join(dir, tid+".json"))
+	var root traceJSON
+	json.Unmarshal(data, &root)
+
+	tool := root.Children[0].Children[0]
+	if tool.Duration != 1500 {
+		t.Errorf("tool duration_ms = %d, want 1500", tool.Duration)
+	}
+	if tool.RunID != "run-1" {
+		t.Errorf("tool run_id = %q, want run-1", tool.RunID)
+	}
+}
+
 func TestCostAccumulatesAcrossCompletions(t *testing.T) {
 	dir := t.TempDir()
 	p := NewProvider(WithDir(dir))
@@ -377,3 +405,49 @@ func TestJSONSchemaMatchesBelayNode(t *testing.T) {
 		}
 	}
 }
+
+func TestJobsAreGroupedWithinPhase(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProvider(WithDir(dir))
+
+	tid := p.StartTrace(belaykit.TraceConfig{Name: "fan-out"}, nil)
+	handler := p.EventHandler()
+
+	handler(belaykit.Event{Type: belaykit.EventPhase, PhaseName: "review"})
+	// Two jobs interleave their tool calls.
+	handler(belaykit.Event{Type: belaykit.EventToolUse, ToolName: "Read", ToolID: "a1", JobID: "a"})
+	handler(belaykit.Event{Type: belaykit.EventToolUse, ToolName: "Grep", ToolID: "b1", JobID: "b"})
+	handler(belaykit.Event{Type: belaykit.EventToolResult, ToolID: "b1", JobID: "b"})
+	handler(belaykit.Event{Type: belaykit.EventToolResult, ToolID: "a1", JobID: "a"})
+	handler(belaykit.Event{Type: belaykit.EventToolUse, ToolName: "Edit", ToolID: "a2", JobID: "a"})
+	p.RecordCompletion(belaykit.CompletionRecord{JobID: "b", CostUSD: 0.02, DurationMS: 100, Model: "sonnet"})
+	p.RecordCompletion(belaykit.CompletionRecord{JobID: "a", CostUSD: 0.05, DurationMS: 300, Model: "opus"})
+	p.EndTrace(tid, nil)
+
+	data, err := os.ReadFile(filepath.Join(dir, tid+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root traceJSON
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	phase := root.Children[1] // [0] is marker
+	if len(phase.Children) != 2 {
+		t.Fatalf("phase children = %d, want 2 job nodes", len(phase.Children))
+	}
+	a, b := phase.Children[0], phase.Children[1]
+	if a.NodeType != "job" || a.AgentName != "a" || len(a.Children) != 2 {
+		t.Errorf("job a = %+v, want 2 tool calls", a)
+	}
+	if b.AgentName != "b" || len(b.Children) != 1 || b.Children[0].AgentName != "Grep" {
+		t.Errorf("job b = %+v, want Grep call", b)
+	}
+	if !approxEqual(a.CostUSD, 0.05, 1e-9) || a.Model != "opus" || a.Duration != 300 {
+		t.Errorf("job a cost/model/duration = %f/%q/%d", a.CostUSD, a.Model, a.Duration)
+	}
+	if phase.CostUSD != 0 {
+		t.Errorf("phase cost_usd = %f, want job costs kept on job nodes", phase.CostUSD)
+	}
+}
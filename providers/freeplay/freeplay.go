@@ -64,7 +64,7 @@ func (p *Provider) RecordCompletion(record belaykit.CompletionRecord) {
 		Prompt:     record.Prompt,
 		Response:   record.Response,
 		Model:      record.Model,
-		Provider:   "anthropic",
+		Provider:   vendorForProvider(record.Provider),
 		StartTime:  start,
 		EndTime:    now,
 		CostUSD:    record.CostUSD,
@@ -72,3 +72,14 @@ func (p *Provider) RecordCompletion(record belaykit.CompletionRecord) {
 		NumTurns:   record.NumTurns,
 	})
 }
+
+// vendorForProvider maps a belaykit provider name to the model vendor name
+// Freeplay expects. Unknown or empty providers default to "anthropic".
+func vendorForProvider(provider string) string {
+	switch provider {
+	case "codex":
+		return "openai"
+	default:
+		return "anthropic"
+	}
+}
@@ -0,0 +1,186 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Verify RetryAgent implements Agent.
+var _ Agent = (*RetryAgent)(nil)
+
+// Classifier decides whether a failed run should be retried. It receives the
+// (possibly partial) result and error returned by the agent.
+type Classifier func(res Result, err error) bool
+
+// IsTransientFailure is the default Classifier for Retry. It retries CLI
+// exits whose stderr reports a rate limit or overload, and error results
+// whose text does. Unlike IsRetryable it does not retry a missing CLI, since
+// retrying the same agent cannot fix that.
+func IsTransientFailure(res Result, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var cliErr CLIError
+	if errors.As(err, &cliErr) && IsTransient(cliErr.CLIStderr()) {
+		return true
+	}
+	return res.IsError && IsTransient(res.Text)
+}
+
+// RetryOption configures a RetryAgent.
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	jitter      float64
+	classifier  Classifier
+	resume      func(sessionID string) RunOption
+}
+
+// RetryMaxAttempts sets the total number of attempts, including the first.
+func RetryMaxAttempts(n int) RetryOption {
+	return func(cfg *retryConfig) { cfg.maxAttempts = n }
+}
+
+// RetryBackoff sets exponential backoff: the delay before retry i is
+// base*2^(i-1), capped at max.
+func RetryBackoff(base, max time.Duration) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.baseDelay = base
+		cfg.maxDelay = max
+	}
+}
+
+// RetryJitter randomizes each delay by up to ±fraction of its length.
+// The fraction is clamped to [0, 1], so a delay never goes negative; a
+// fraction of 0 disables jitter.
+func RetryJitter(fraction float64) RetryOption {
+	return func(cfg *retryConfig) { cfg.jitter = min(max(fraction, 0), 1) }
+}
+
+// RetryClassifier overrides the default IsTransientFailure classifier.
+func RetryClassifier(fn Classifier) RetryOption {
+	return func(cfg *retryConfig) { cfg.classifier = fn }
+}
+
+// RetryResume continues the failed run's session instead of starting over.
+// fn maps a session ID to the run option that resumes it; it is used only
+// when the failed attempt reported a session ID.
+func RetryResume(fn func(sessionID string) RunOption) RetryOption {
+	return func(cfg *retryConfig) { cfg.resume = fn }
+}
+
+// RetryAgent retries transient failures of an underlying Agent with
+// exponential backoff.
+type RetryAgent struct {
+	agent Agent
+	cfg   retryConfig
+	sleep func(ctx context.Context, d time.Duration) error // for testing
+}
+
+// Retry wraps agent so transient failures are retried. By default it makes
+// 3 attempts with backoff starting at 1s, capped at 30s, with 20% jitter.
+func Retry(agent Agent, opts ...RetryOption) *RetryAgent {
+	cfg := retryConfig{
+		maxAttempts: 3,
+		baseDelay:   time.Second,
+		maxDelay:    30 * time.Second,
+		jitter:      0.2,
+		classifier:  IsTransientFailure,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &RetryAgent{agent: agent, cfg: cfg, sleep: sleepContext}
+}
+
+// EnforcesOutputSchema reports whether the wrapped agent does (see
+// SchemaEnforcer).
+func (r *RetryAgent) EnforcesOutputSchema() bool {
+	return enforcesOutputSchema(r.agent)
+}
+
+// DefaultEventHandler returns the wrapped agent's default event handler
+// (see the DefaultEventHandler function).
+func (r *RetryAgent) DefaultEventHandler() EventHandler {
+	return DefaultEventHandler(r.agent)
+}
+
+// Run executes the prompt, retrying while the classifier reports the
+// failure as transient. Before every attempt an EventAttempt event is sent
+// where the wrapped agent sends its events: the per-run event handler or
+// else the agent's default (see DefaultEventHandler), and the hooks.
+func (r *RetryAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	handler := NewRunConfig(opts...).ResolveEventHandler(DefaultEventHandler(r.agent))
+	attempts := max(r.cfg.maxAttempts, 1)
+
+	var res Result
+	var err error
+	var reason string
+	runOpts := opts
+	for i := range attempts {
+		if i > 0 {
+			if serr := r.sleep(ctx, r.delay(i)); serr != nil {
+				return res, serr
+			}
+		}
+		if handler != nil {
+			handler(Event{
+				Type:     EventAttempt,
+				Attempt:  i + 1,
+				Provider: AgentName(r.agent),
+				Text:     reason,
+				Time:     time.Now(),
+			})
+		}
+
+		res, err = r.agent.Run(ctx, prompt, runOpts...)
+		if err == nil && !res.IsError {
+			return res, nil
+		}
+		if ctx.Err() != nil || !r.cfg.classifier(res, err) {
+			return res, err
+		}
+		reason = failureReason(res, err)
+		if r.cfg.resume != nil && res.SessionID != "" {
+			runOpts = append(append([]RunOption(nil), opts...), r.cfg.resume(res.SessionID))
+		}
+	}
+
+	if err != nil {
+		return res, fmt.Errorf("after %d attempts: %w", attempts, err)
+	}
+	return res, nil
+}
+
+// delay returns the backoff before retry number i (1-based).
+func (r *RetryAgent) delay(i int) time.Duration {
+	d := r.cfg.baseDelay
+	for range i - 1 {
+		if r.cfg.maxDelay > 0 && d >= r.cfg.maxDelay {
+			break
+		}
+		d *= 2
+	}
+	if r.cfg.maxDelay > 0 && d > r.cfg.maxDelay {
+		d = r.cfg.maxDelay
+	}
+	if r.cfg.jitter > 0 {
+		d = time.Duration(float64(d) * (1 - r.cfg.jitter + 2*r.cfg.jitter*rand.Float64()))
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
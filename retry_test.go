@@ -0,0 +1,160 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// scriptedAgent returns the next scripted result on each call and records
+// the options it was called with.
+type scriptedAgent struct {
+	results []Result
+	errs    []error
+	configs []RunConfig
+}
+
+func (a *scriptedAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	i := len(a.configs)
+	a.configs = append(a.configs, NewRunConfig(opts...))
+	return a.results[i], a.errs[i]
+}
+
+func noSleep(r *RetryAgent) *[]time.Duration {
+	var delays []time.Duration
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return &delays
+}
+
+func TestRetryTransientThenSuccess(t *testing.T) {
+	agent := &scriptedAgent{
+		results: []Result{{SessionID: "sess-1"}, {IsError: true, Text: "Overloaded"}, {Text: "ok"}},
+		errs:    []error{&stubCLIError{"API Error: 529 overloaded_error"}, nil, nil},
+	}
+	r := Retry(agent,
+		RetryBackoff(time.Second, 3*time.Second),
+		RetryJitter(0),
+		RetryResume(func(id string) RunOption { return WithTraceID("resume:" + id) }),
+	)
+	delays := noSleep(r)
+
+	var attempts int
+	res, err := r.Run(t.Context(), "hi", WithEventHandler(func(e Event) {
+		if e.Type == EventAttempt {
+			attempts++
+		}
+	}))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if res.Text != "ok" {
+		t.Errorf("Text = %q, want %q", res.Text, "ok")
+	}
+	if attempts != 3 {
+		t.Errorf("attempt events = %d, want 3", attempts)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(*delays) != 2 || (*delays)[0] != want[0] || (*delays)[1] != want[1] {
+		t.Errorf("delays = %v, want %v", *delays, want)
+	}
+	if agent.configs[0].TraceID != "" {
+		t.Errorf("first attempt should not resume, got %q", agent.configs[0].TraceID)
+	}
+	if agent.configs[1].TraceID != "resume:sess-1" {
+		t.Errorf("second attempt TraceID = %q, want resumed session", agent.configs[1].TraceID)
+	}
+}
+
+func TestRetryStopsOnPermanentError(t *testing.T) {
+	agent := &scriptedAgent{
+		results: []Result{{}, {}},
+		errs:    []error{&stubCLIError{"unknown flag --foo"}, nil},
+	}
+	r := Retry(agent)
+	noSleep(r)
+
+	_, err := r.Run(t.Context(), "hi")
+	var cliErr CLIError
+	if !errors.As(err, &cliErr) {
+		t.Fatalf("err = %v, want CLIError", err)
+	}
+	if len(agent.configs) != 1 {
+		t.Errorf("calls = %d, want 1", len(agent.configs))
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	overloaded := &stubCLIError{"overloaded"}
+	agent := &scriptedAgent{
+		results: []Result{{}, {}},
+		errs:    []error{overloaded, overloaded},
+	}
+	r := Retry(agent, RetryMaxAttempts(2))
+	noSleep(r)
+
+	_, err := r.Run(t.Context(), "hi")
+	if !errors.Is(err, overloaded) {
+		t.Fatalf("err = %v, want wrapped overload error", err)
+	}
+	if len(agent.configs) != 2 {
+		t.Errorf("calls = %d, want 2", len(agent.configs))
+	}
+}
+
+func TestRetryCustomClassifier(t *testing.T) {
+	boom := errors.New("boom")
+	agent := &scriptedAgent{
+		results: []Result{{}, {Text: "ok"}},
+		errs:    []error{boom, nil},
+	}
+	r := Retry(agent, RetryClassifier(func(res Result, err error) bool { return errors.Is(err, boom) }))
+	noSleep(r)
+
+	res, err := r.Run(t.Context(), "hi")
+	if err != nil || res.Text != "ok" {
+		t.Fatalf("Run = %+v, %v", res, err)
+	}
+}
+
+func TestRetryCanceledDuringBackoff(t *testing.T) {
+	agent := &scriptedAgent{
+		results: []Result{{}, {}},
+		errs:    []error{&stubCLIError{"rate limit"}, nil},
+	}
+	r := Retry(agent, RetryBackoff(time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	_, err := r.Run(ctx, "hi")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if len(agent.configs) != 1 {
+		t.Errorf("calls = %d, want 1", len(agent.configs))
+	}
+}
+
+func TestRetryDelayCapped(t *testing.T) {
+	r := Retry(nil, RetryBackoff(time.Second, 5*time.Second), RetryJitter(0))
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := r.delay(i + 1); got != w {
+			t.Errorf("delay(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRetryJitterClamped(t *testing.T) {
+	r := Retry(nil, RetryBackoff(time.Second, time.Second), RetryJitter(5))
+	for range 100 {
+		if d := r.delay(1); d < 0 || d > 2*time.Second {
+			t.Fatalf("delay = %v, want within [0, 2s]", d)
+		}
+	}
+	if r := Retry(nil, RetryJitter(-1)); r.cfg.jitter != 0 {
+		t.Errorf("negative jitter = %v, want 0", r.cfg.jitter)
+	}
+}
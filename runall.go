@@ -0,0 +1,169 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// DefaultConcurrency is how many jobs RunAll runs at once unless
+// RunAllConcurrency says otherwise.
+const DefaultConcurrency = 4
+
+// ErrJobSkipped is the error recorded for jobs RunAll never started,
+// because it stopped early.
+var ErrJobSkipped = errors.New("job skipped")
+
+// Job is a single prompt for RunAll.
+type Job struct {
+	ID     string      // Tags the job's events and result; defaults to its index
+	Prompt string      // Prompt to run
+	Opts   []RunOption // Per-job options, applied after RunAllRunOptions
+}
+
+// JobResult is the outcome of one Job.
+type JobResult struct {
+	ID     string
+	Result Result
+	Err    error
+}
+
+// RunAllOption configures RunAll.
+type RunAllOption func(*runAllConfig)
+
+type runAllConfig struct {
+	concurrency int
+	failFast    bool
+	opts        []RunOption
+	onDone      func(int, JobResult)
+}
+
+// RunAllConcurrency sets how many jobs run at once. Defaults to
+// DefaultConcurrency.
+func RunAllConcurrency(n int) RunAllOption {
+	return func(cfg *runAllConfig) { cfg.concurrency = n }
+}
+
+// RunAllFailFast stops RunAll on the first failed job: no further jobs are
+// started and jobs in flight are interrupted.
+func RunAllFailFast() RunAllOption {
+	return func(cfg *runAllConfig) { cfg.failFast = true }
+}
+
+// RunAllRunOptions sets options applied to every job, before the job's own.
+func RunAllRunOptions(opts ...RunOption) RunAllOption {
+	return func(cfg *runAllConfig) { cfg.opts = append(cfg.opts, opts...) }
+}
+
+// RunAllOnDone calls fn with each job's index and result as soon as the job
+// finishes, before RunAll returns. Calls are serialized, so fn may write to
+// a shared writer without locking.
+func RunAllOnDone(fn func(i int, r JobResult)) RunAllOption {
+	return func(cfg *runAllConfig) { cfg.onDone = fn }
+}
+
+// RunAll runs jobs on agent with bounded concurrency and returns one
+// JobResult per job, in the order of jobs. Each job's events and completion
+// record are tagged with its ID (see WithJobID).
+//
+// RunAll stops starting jobs when ctx is done, when the shared Budget from
+// RunAllRunOptions is exhausted, or, with RunAllFailFast, when a job fails.
+// Jobs that never started have ErrJobSkipped as their error, and RunAll
+// returns the reason it stopped. Otherwise it returns nil; check each
+// JobResult for per-job errors.
+func RunAll(ctx context.Context, agent Agent, jobs []Job, opts ...RunAllOption) ([]JobResult, error) {
+	cfg := runAllConfig{concurrency: DefaultConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.concurrency = max(cfg.concurrency, 1)
+	budget := NewRunConfig(cfg.opts...).Budget
+
+	results := make([]JobResult, len(jobs))
+	for i, job := range jobs {
+		id := job.ID
+		if id == "" {
+			id = strconv.Itoa(i)
+		}
+		results[i] = JobResult{ID: id, Err: ErrJobSkipped}
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		doneMu  sync.Mutex
+		stopErr error
+	)
+	stop := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopErr == nil {
+			stopErr = err
+		}
+	}
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return stopErr != nil
+	}
+
+	sem := make(chan struct{}, cfg.concurrency)
+	for i, job := range jobs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil || stopped() {
+			break
+		}
+		if budget != nil && budget.Exceeded() {
+			stop(budget.Err())
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			id := results[i].ID
+			runOpts := slices.Concat(cfg.opts, job.Opts, []RunOption{WithJobID(id)})
+			res, err := agent.Run(ctx, job.Prompt, runOpts...)
+			results[i] = JobResult{ID: id, Result: res, Err: err}
+			if cfg.onDone != nil {
+				doneMu.Lock()
+				cfg.onDone(i, results[i])
+				doneMu.Unlock()
+			}
+
+			var budgetErr *BudgetExceededError
+			switch {
+			case errors.As(err, &budgetErr) && budgetErr.Scope == BudgetScopeShared:
+				stop(err)
+			case cfg.failFast && (err != nil || res.IsError):
+				if err == nil {
+					err = errors.New(res.Text)
+				}
+				err = fmt.Errorf("job %s: %w", id, err)
+				stop(err)
+				cancel(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stopErr != nil {
+		return results, stopErr
+	}
+	if parent.Err() != nil {
+		return results, context.Cause(parent)
+	}
+	return results, nil
+}
@@ -0,0 +1,162 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jobAgent echoes prompts, failing prompts listed in fail, and tracks peak
+// concurrency.
+type jobAgent struct {
+	fail    map[string]bool
+	delay   time.Duration
+	cost    float64
+	running atomic.Int32
+	peak    atomic.Int32
+
+	mu     sync.Mutex
+	events []Event
+}
+
+func (a *jobAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	n := a.running.Add(1)
+	defer a.running.Add(-1)
+	for {
+		p := a.peak.Load()
+		if n <= p || a.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+
+	cfg := NewRunConfig(opts...)
+	if err := cfg.CheckBudget(); err != nil {
+		return Result{}, err
+	}
+	if h := cfg.ResolveEventHandler(nil); h != nil {
+		h(Event{Type: EventAssistant, Text: prompt})
+	}
+
+	select {
+	case <-time.After(a.delay):
+	case <-ctx.Done():
+		return Result{}, fmt.Errorf("%w: %w", ErrInterrupted, context.Cause(ctx))
+	}
+	if a.fail[prompt] {
+		return Result{Text: prompt}, errors.New("failed: " + prompt)
+	}
+	if b := cfg.Budget; b != nil {
+		tr := NewRunTracker("")
+		b.commit(tr, a.cost)
+	}
+	return Result{Text: "echo " + prompt, CostUSD: a.cost}, nil
+}
+
+func TestRunAllOrderedResults(t *testing.T) {
+	agent := &jobAgent{fail: map[string]bool{"p2": true}, delay: 10 * time.Millisecond}
+	jobs := make([]Job, 6)
+	for i := range jobs {
+		jobs[i] = Job{Prompt: fmt.Sprintf("p%d", i)}
+	}
+	jobs[0].ID = "first"
+
+	var mu sync.Mutex
+	tagged := map[string]int{}
+	results, err := RunAll(t.Context(), agent, jobs,
+		RunAllConcurrency(2),
+		RunAllRunOptions(WithEventHandler(func(e Event) {
+			mu.Lock()
+			tagged[e.JobID]++
+			mu.Unlock()
+		})),
+	)
+	if err != nil {
+		t.Fatalf("RunAll error: %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("results = %d, want 6", len(results))
+	}
+	for i, r := range results {
+		if i == 2 {
+			if r.Err == nil {
+				t.Errorf("job 2 should have failed")
+			}
+			continue
+		}
+		if r.Err != nil || r.Result.Text != fmt.Sprintf("echo p%d", i) {
+			t.Errorf("results[%d] = %+v", i, r)
+		}
+	}
+	if results[0].ID != "first" || results[3].ID != "3" {
+		t.Errorf("IDs = %q, %q", results[0].ID, results[3].ID)
+	}
+	if got := agent.peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", got)
+	}
+	if tagged["first"] != 1 || tagged["5"] != 1 || tagged[""] != 0 {
+		t.Errorf("events per job = %v", tagged)
+	}
+}
+
+func TestRunAllFailFast(t *testing.T) {
+	// "bad" fails while "slow" is still running; "later" never starts.
+	agent := &jobAgent{fail: map[string]bool{"bad": true}, delay: 20 * time.Millisecond}
+	slow := &jobAgent{delay: time.Minute}
+	jobs := []Job{{Prompt: "bad"}, {Prompt: "slow"}, {Prompt: "later"}}
+
+	results, err := RunAll(t.Context(), AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+		if prompt == "slow" {
+			return slow.Run(ctx, prompt, opts...)
+		}
+		return agent.Run(ctx, prompt, opts...)
+	}), jobs, RunAllConcurrency(2), RunAllFailFast())
+
+	if err == nil || err.Error() != "job 0: failed: bad" {
+		t.Fatalf("err = %v, want first job failure", err)
+	}
+	if !errors.Is(results[1].Err, ErrInterrupted) {
+		t.Errorf("in-flight job err = %v, want ErrInterrupted", results[1].Err)
+	}
+	if !errors.Is(results[2].Err, ErrJobSkipped) {
+		t.Errorf("pending job err = %v, want ErrJobSkipped", results[2].Err)
+	}
+}
+
+func TestRunAllStopsOnBudget(t *testing.T) {
+	agent := &jobAgent{cost: 0.6}
+	budget := NewBudget(1)
+	jobs := []Job{{Prompt: "a"}, {Prompt: "b"}, {Prompt: "c"}, {Prompt: "d"}}
+
+	results, err := RunAll(t.Context(), agent, jobs, RunAllConcurrency(1), RunAllRunOptions(WithBudget(budget)))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("err = %v, want ErrBudgetExceeded", err)
+	}
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("first jobs should run until the budget is spent: %+v", results[:2])
+	}
+	for _, r := range results[2:] {
+		if !errors.Is(r.Err, ErrJobSkipped) {
+			t.Errorf("job %s err = %v, want ErrJobSkipped", r.ID, r.Err)
+		}
+	}
+}
+
+func TestRunAllOnDone(t *testing.T) {
+	agent := &jobAgent{}
+	jobs := []Job{{Prompt: "a"}, {Prompt: "b"}, {Prompt: "c"}}
+
+	seen := map[int]string{}
+	_, err := RunAll(t.Context(), agent, jobs, RunAllOnDone(func(i int, r JobResult) {
+		seen[i] = r.Result.Text
+	}))
+	if err != nil {
+		t.Fatalf("RunAll error: %v", err)
+	}
+	if len(seen) != 3 || seen[1] != "echo b" {
+		t.Errorf("OnDone calls = %v", seen)
+	}
+}
@@ -0,0 +1,69 @@
+package belaykit
+
+import (
+	"context"
+	"iter"
+	"slices"
+)
+
+// RunStream runs agent and returns an iterator over the run's events, for
+// use with a range loop:
+//
+//	var res belaykit.Result
+//	for ev, err := range belaykit.RunStream(ctx, client, prompt, &res) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Print(ev.Text)
+//	}
+//	fmt.Println(res.CostUSD)
+//
+// The run starts when the loop does, through the agent's Start method if it
+// is a Starter. If the run fails, its error is yielded last with a zero
+// Event. Breaking out of the loop interrupts the run and waits for it to
+// stop. When the loop ends, the run's final (or partial) Result is stored
+// in *res unless res is nil.
+func RunStream(ctx context.Context, agent Agent, prompt string, res *Result, opts ...RunOption) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		opts := append(slices.Clip(opts), watchEvents(true))
+		var h *RunHandle
+		if s, ok := agent.(Starter); ok {
+			h = s.Start(ctx, prompt, opts...)
+		} else {
+			h = StartRun(ctx, agent, prompt, opts...)
+		}
+		h.Stream()(yield)
+		if res != nil {
+			// Stream returns only once the run is over.
+			*res, _ = h.Wait()
+		}
+	}
+}
+
+// Stream returns an iterator over the run's events, like RunStream, from the
+// moment it is called (see Events). After the loop, Wait returns the run's
+// Result without blocking. A handle's events can be read only once, through
+// either Stream or Events.
+//
+//	h := client.Start(ctx, prompt)
+//	for ev, err := range h.Stream() {
+//	    ...
+//	}
+//	res, err := h.Wait()
+func (h *RunHandle) Stream() iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		events := h.Events()
+		for e := range events {
+			if !yield(e, nil) {
+				h.Interrupt()
+				for range events {
+					// Drain until the run has stopped.
+				}
+				return
+			}
+		}
+		if _, err := h.Wait(); err != nil {
+			yield(Event{}, err)
+		}
+	}
+}
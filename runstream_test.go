@@ -0,0 +1,92 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunStream(t *testing.T) {
+	agent := &gatedAgent{release: make(chan struct{})}
+	close(agent.release)
+
+	var res Result
+	var types []EventType
+	for e, err := range RunStream(t.Context(), agent, "go", &res) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		types = append(types, e.Type)
+	}
+	want := []EventType{EventSystem, EventToolUse, EventToolResult, EventResult}
+	if len(types) != len(want) || types[3] != EventResult {
+		t.Errorf("events = %v, want %v", types, want)
+	}
+	if res.Text != "done" || res.CostUSD != 0.25 || res.SessionID != "sess-1" {
+		t.Errorf("Result = %+v, want the final result", res)
+	}
+}
+
+func TestRunStreamBreakInterrupts(t *testing.T) {
+	h := StartRun(t.Context(), &gatedAgent{}, "go")
+	for e, err := range h.Stream() {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		if e.Type == EventToolUse {
+			break
+		}
+	}
+
+	select {
+	case <-h.Done():
+	default:
+		t.Fatal("run still going after the loop was broken")
+	}
+	res, err := h.Wait()
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want interrupted run", err)
+	}
+	if res.SessionID != "sess-1" || len(res.ToolCalls) != 1 {
+		t.Errorf("Result = %+v, want the partial run", res)
+	}
+}
+
+func TestRunStreamYieldsRunError(t *testing.T) {
+	boom := errors.New("boom")
+	agent := AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+		return Result{}, boom
+	})
+
+	var n int
+	var last error
+	for e, err := range RunStream(t.Context(), agent, "go", nil) {
+		n++
+		if e.Type != "" {
+			t.Errorf("unexpected event %s", e.Type)
+		}
+		last = err
+	}
+	if n != 1 || !errors.Is(last, boom) {
+		t.Errorf("got %d items, last error %v; want one boom", n, last)
+	}
+}
+
+func TestRunStreamDoesNotWatchNestedRuns(t *testing.T) {
+	var nested *RunHandle
+	agent := AgentFunc(func(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+		nested = StartRun(ctx, AgentFunc(func(context.Context, string, ...RunOption) (Result, error) {
+			return Result{}, nil
+		}), prompt, opts...)
+		return nested.Wait()
+	})
+
+	for _, err := range RunStream(t.Context(), agent, "go", nil) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+	}
+	if nested.watched {
+		t.Error("a run started by the streamed agent keeps events for Events")
+	}
+}
@@ -0,0 +1,302 @@
+// Package schema builds JSON Schemas from Go types and validates decoded
+// JSON against them. It covers the subset of JSON Schema that agent output
+// needs: types, properties, required fields, items, enums and descriptions.
+//
+// Usage:
+//
+//	type Review struct {
+//	    Verdict string   `json:"verdict" enum:"approve,reject" description:"Overall decision"`
+//	    Issues  []string `json:"issues,omitempty"`
+//	}
+//
+//	s := schema.For[Review]()
+//	client.Run(ctx, prompt, belaykit.WithOutputSchema(s))
+//
+// Field names follow encoding/json. Fields are required unless they are
+// tagged omitempty or omitzero, or are pointers; a `required:"true"` or
+// `required:"false"` tag overrides this.
+//
+// APIs that enforce schemas strictly, such as codex's --output-schema, accept
+// only a subset of JSON Schema; Strict converts a schema to it.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotStrict indicates a schema that Strict cannot express in strict
+// mode.
+var ErrNotStrict = errors.New("schema not supported in strict mode")
+
+// Schema is a JSON Schema document.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"-"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	// Closed disallows properties not listed in Properties. Reflected
+	// structs are closed.
+	Closed bool `json:"-"`
+
+	// Nullable also allows null, written as a type of [Type, "null"].
+	Nullable bool `json:"-"`
+}
+
+// MarshalJSON encodes the schema, writing additionalProperties from
+// AdditionalProperties or Closed, and type from Type and Nullable.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		Type any `json:"type,omitempty"`
+		*plain
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+	if s.Type != "" {
+		out.Type = s.Type
+		if s.Nullable {
+			out.Type = []string{s.Type, "null"}
+		}
+	}
+	switch {
+	case s.AdditionalProperties != nil:
+		out.AdditionalProperties = s.AdditionalProperties
+	case s.Closed:
+		out.AdditionalProperties = false
+	}
+	return json.Marshal(out)
+}
+
+// String returns the schema as indented JSON.
+func (s *Schema) String() string {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<invalid schema: %v>", err)
+	}
+	return string(data)
+}
+
+// Prompt returns instructions asking a model to reply with JSON matching s,
+// suitable for appending to a prompt or system prompt.
+func (s *Schema) Prompt() string {
+	kind := "value"
+	switch s.Type {
+	case "object", "array":
+		kind = s.Type
+	}
+	return fmt.Sprintf("Respond with a single JSON %s matching this JSON Schema:\n\n```json\n%s\n```", kind, s)
+}
+
+// Strict returns a copy of s in the subset of JSON Schema that strict
+// structured-output APIs accept: the root is an object, every object is
+// closed and lists all of its properties as required, and properties that
+// were optional become nullable instead. Maps, values of any type (such as
+// json.RawMessage or recursive types) and non-object roots cannot be
+// expressed; the error wraps ErrNotStrict and names the offending path.
+//
+// Values decoded from a reply to the strict schema still validate against
+// s, since optional fields may be null.
+func (s *Schema) Strict() (*Schema, error) {
+	if s.Type != "object" {
+		return nil, fmt.Errorf("%w: $: the root must be an object, got %s", ErrNotStrict, describeType(s))
+	}
+	return s.strict("$")
+}
+
+func (s *Schema) strict(path string) (*Schema, error) {
+	switch {
+	case s.Type == "":
+		return nil, fmt.Errorf("%w: %s: values of any type are not allowed", ErrNotStrict, path)
+	case s.AdditionalProperties != nil:
+		return nil, fmt.Errorf("%w: %s: maps are not allowed", ErrNotStrict, path)
+	}
+
+	c := *s
+	c.Enum = slices.Clone(s.Enum)
+	switch s.Type {
+	case "object":
+		c.Closed = true
+		c.Properties = make(map[string]*Schema, len(s.Properties))
+		c.Required = nil
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, err := s.Properties[name].strict(path + "." + name)
+			if err != nil {
+				return nil, err
+			}
+			if !slices.Contains(s.Required, name) && !prop.Nullable {
+				prop.Nullable = true
+				if len(prop.Enum) > 0 {
+					prop.Enum = append(prop.Enum, nil)
+				}
+			}
+			c.Properties[name] = prop
+			c.Required = append(c.Required, name)
+		}
+	case "array":
+		if s.Items == nil {
+			return nil, fmt.Errorf("%w: %s: array items of any type are not allowed", ErrNotStrict, path)
+		}
+		items, err := s.Items.strict(path + "[]")
+		if err != nil {
+			return nil, err
+		}
+		c.Items = items
+	}
+	return &c, nil
+}
+
+func describeType(s *Schema) string {
+	if s.Type == "" {
+		return "any type"
+	}
+	return "type " + s.Type
+}
+
+// For returns the schema for T.
+func For[T any]() *Schema {
+	return Reflect(reflect.TypeFor[T]())
+}
+
+// Reflect returns the schema for typ.
+func Reflect(typ reflect.Type) *Schema {
+	return reflectType(typ, nil)
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// reflectType describes typ. seen guards against recursive types, which are
+// described as an unconstrained value.
+func reflectType(typ reflect.Type, seen map[reflect.Type]bool) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes []byte as base64, but [N]byte as numbers.
+		if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: reflectType(typ.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: reflectType(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return &Schema{}
+		}
+		if seen == nil {
+			seen = make(map[reflect.Type]bool)
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, Closed: true}
+		addFields(s, typ, seen)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// addFields adds typ's JSON-visible fields to s, flattening embedded
+// structs the way encoding/json does.
+func addFields(s *Schema, typ reflect.Type, seen map[reflect.Type]bool) {
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addFields(s, ft, seen)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := reflectType(f.Type, seen)
+		prop.Description = f.Tag.Get("description")
+		if enum := f.Tag.Get("enum"); enum != "" {
+			prop.Enum = parseEnum(enum, prop.Type)
+		}
+		s.Properties[name] = prop
+
+		required := f.Type.Kind() != reflect.Pointer &&
+			!strings.Contains(flags, "omitempty") && !strings.Contains(flags, "omitzero")
+		if r, err := strconv.ParseBool(f.Tag.Get("required")); err == nil {
+			required = r
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// parseEnum splits a comma-separated enum tag into values of the field's
+// JSON type. Values that do not parse are kept as strings.
+func parseEnum(tag, typ string) []any {
+	var values []any
+	for v := range strings.SplitSeq(tag, ",") {
+		v = strings.TrimSpace(v)
+		switch typ {
+		case "integer", "number":
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				values = append(values, n)
+				continue
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(v); err == nil {
+				values = append(values, b)
+				continue
+			}
+		}
+		values = append(values, v)
+	}
+	return values
+}
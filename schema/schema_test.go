@@ -0,0 +1,222 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type issue struct {
+	File     string `json:"file" description:"Path relative to the repo root"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity" enum:"low,medium,high"`
+}
+
+type review struct {
+	Verdict string   `json:"verdict" enum:"approve,reject"`
+	Issues  []issue  `json:"issues,omitempty"`
+	Score   *float64 `json:"score"`
+	Notes   string   `json:"notes,omitempty" required:"true"`
+	Labels  map[string]int
+	Skip    string `json:"-"`
+	private string
+}
+
+func TestFor(t *testing.T) {
+	got, err := json.Marshal(For[review]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"object","properties":{` +
+		`"Labels":{"type":"object","additionalProperties":{"type":"integer"}},` +
+		`"issues":{"type":"array","items":{"type":"object","properties":{` +
+		`"file":{"type":"string","description":"Path relative to the repo root"},` +
+		`"line":{"type":"integer"},` +
+		`"severity":{"type":"string","enum":["low","medium","high"]}},` +
+		`"required":["file","line","severity"],"additionalProperties":false}},` +
+		`"notes":{"type":"string"},` +
+		`"score":{"type":"number"},` +
+		`"verdict":{"type":"string","enum":["approve","reject"]}},` +
+		`"required":["verdict","notes","Labels"],"additionalProperties":false}`
+	if string(got) != want {
+		t.Errorf("schema =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestForBytes(t *testing.T) {
+	type blob struct {
+		Data []byte  `json:"data"`
+		Hash [4]byte `json:"hash"`
+	}
+	s := For[blob]()
+	if got := s.Properties["data"].Type; got != "string" {
+		t.Errorf("[]byte type = %v, want string", got)
+	}
+	if h := s.Properties["hash"]; h.Type != "array" || h.Items == nil || h.Items.Type != "integer" {
+		t.Errorf("[4]byte schema = %+v, want an array of integers", h)
+	}
+
+	data, err := json.Marshal(blob{Data: []byte("hi"), Hash: [4]byte{1, 2, 3, 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidateJSON(data); err != nil {
+		t.Errorf("encoded value %s fails validation: %v", data, err)
+	}
+}
+
+func TestForRecursiveAndEmbedded(t *testing.T) {
+	type node struct {
+		Name     string  `json:"name"`
+		Children []*node `json:"children,omitempty"`
+	}
+	type wrapper struct {
+		node
+		Level int `json:"level" enum:"1,2,3"`
+	}
+
+	s := For[wrapper]()
+	if _, ok := s.Properties["name"]; !ok {
+		t.Errorf("embedded fields not flattened: %v", s.Properties)
+	}
+	if items := s.Properties["children"].Items; items.Type != "object" || items.Properties["children"].Items.Type != "" {
+		t.Errorf("recursive type not cut off: %s", s)
+	}
+	if enum := s.Properties["level"].Enum; len(enum) != 3 || enum[0] != 1.0 {
+		t.Errorf("integer enum = %v", enum)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := For[review]()
+	tests := []struct {
+		name string
+		json string
+		want []string
+	}{
+		{
+			name: "valid",
+			json: `{"verdict":"approve","notes":"","score":0.5,"Labels":{},"issues":[{"file":"a.go","line":3,"severity":"low"}]}`,
+		},
+		{
+			name: "null optional field",
+			json: `{"verdict":"reject","notes":"","score":null,"Labels":{}}`,
+		},
+		{
+			name: "missing and unexpected fields",
+			json: `{"verdict":"approve","Labels":{},"extra":true}`,
+			want: []string{`$: missing required field "notes"`, `$.extra: unexpected field`},
+		},
+		{
+			name: "nested errors",
+			json: `{"verdict":"maybe","notes":"","Labels":{"x":1.5},"issues":[{"file":"a.go","line":"3","severity":"low"},{"file":"b.go","line":1}]}`,
+			want: []string{
+				`$.Labels.x: expected integer, got number`,
+				`$.issues[0].line: expected integer, got string`,
+				`$.issues[1]: missing required field "severity"`,
+				`$.verdict: value "maybe" is not one of ["approve","reject"]`,
+			},
+		},
+		{
+			name: "wrong root type",
+			json: `[1, 2]`,
+			want: []string{`$: expected object, got array`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateJSON([]byte(tt.json))
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateJSON error: %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("err = %v, want *ValidationError", err)
+			}
+			var got []string
+			for _, fe := range verr.Errors {
+				got = append(got, fe.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestStrict(t *testing.T) {
+	type finding struct {
+		Title    string `json:"title"`
+		Severity string `json:"severity,omitempty" enum:"low,high"`
+	}
+	type report struct {
+		Summary  string    `json:"summary"`
+		Findings []finding `json:"findings,omitempty"`
+		Owner    *finding  `json:"owner"`
+	}
+
+	base := For[report]()
+	s, err := base.Strict()
+	if err != nil {
+		t.Fatalf("Strict error: %v", err)
+	}
+	got, _ := json.Marshal(s)
+	want := `{"type":"object","properties":{"findings":{"type":["array","null"],"items":{"type":"object","properties":{"severity":{"type":["string","null"],"enum":["low","high",null]},"title":{"type":"string"}},"required":["severity","title"],"additionalProperties":false}},"owner":{"type":["object","null"],"properties":{"severity":{"type":["string","null"],"enum":["low","high",null]},"title":{"type":"string"}},"required":["severity","title"],"additionalProperties":false},"summary":{"type":"string"}},"required":["findings","owner","summary"],"additionalProperties":false}`
+	if string(got) != want {
+		t.Errorf("Strict() =\n%s\nwant\n%s", got, want)
+	}
+	if base.Properties["owner"].Nullable || len(base.Required) != 1 {
+		t.Error("Strict modified the original schema")
+	}
+
+	// Replies to the strict schema fill optional fields with null, which
+	// both schemas accept.
+	reply := []byte(`{"summary": "ok", "findings": [{"title": "x", "severity": null}], "owner": null}`)
+	for name, sch := range map[string]*Schema{"strict": s, "original": base} {
+		if err := sch.ValidateJSON(reply); err != nil {
+			t.Errorf("%s schema rejected null optional fields: %v", name, err)
+		}
+	}
+	if err := s.ValidateJSON([]byte(`{"summary": null, "findings": null, "owner": null}`)); err == nil {
+		t.Error("strict schema accepted null for a required field")
+	}
+}
+
+func TestStrictUnsupported(t *testing.T) {
+	type withMap struct {
+		Counts map[string]int `json:"counts"`
+	}
+	type withRaw struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	tests := []struct {
+		name   string
+		schema *Schema
+		want   string
+	}{
+		{name: "array root", schema: For[[]string](), want: "$: the root must be an object, got type array"},
+		{name: "map", schema: For[withMap](), want: "$.counts: maps are not allowed"},
+		{name: "any value", schema: For[withRaw](), want: "$.items[]: values of any type are not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.schema.Strict()
+			if !errors.Is(err, ErrNotStrict) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Strict() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	p := For[[]issue]().Prompt()
+	if !strings.HasPrefix(p, "Respond with a single JSON array") || !strings.Contains(p, `"severity"`) {
+		t.Errorf("Prompt() = %q", p)
+	}
+}
@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a single validation failure at a JSON path such as
+// "$.issues[2].line".
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists every way a value failed to match a schema.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "schema validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks a decoded JSON value (as produced by json.Unmarshal into
+// an any) against s. Optional object fields may be null. It returns a
+// *ValidationError listing every mismatch, or nil.
+func (s *Schema) Validate(v any) error {
+	var errs []*FieldError
+	s.validate("$", v, &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateJSON decodes data and validates it against s.
+func (s *Schema) ValidateJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("parsing JSON: %w", err)
+	}
+	return s.Validate(v)
+}
+
+func (s *Schema) validate(path string, v any, errs *[]*FieldError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if v == nil && s.Nullable {
+		return
+	}
+	if got := typeOf(v); s.Type != "" && got != s.Type && !(s.Type == "number" && got == "integer") {
+		fail("expected %s, got %s", s.Type, got)
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		fail("value %s is not one of %s", describe(v), describe(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required field %q", name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "." + k
+			if v[k] == nil && !slices.Contains(s.Required, k) {
+				continue // optional fields may be null
+			}
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(child, v[k], errs)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(child, v[k], errs)
+			} else if s.Closed {
+				*errs = append(*errs, &FieldError{Path: child, Message: "unexpected field"})
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(path+"["+strconv.Itoa(i)+"]", item, errs)
+			}
+		}
+	}
+}
+
+// typeOf returns the JSON Schema type of a decoded JSON value.
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// equal compares an enum value with a decoded JSON value, treating numbers
+// by value.
+func equal(enum, v any) bool {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return false
+		}
+		v = f
+	}
+	if i, ok := enum.(int); ok {
+		enum = float64(i)
+	}
+	return enum == v
+}
+
+func describe(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -14,10 +14,13 @@ type Config struct {
 // EventConfig controls which event types trigger automatic Slack notifications
 // when using NewEventHandler.
 type EventConfig struct {
-	OnError   bool `yaml:"on_error" json:"on_error"`
-	OnResult  bool `yaml:"on_result" json:"on_result"`
-	OnStart   bool `yaml:"on_start" json:"on_start"`
-	OnToolUse bool `yaml:"on_tool_use" json:"on_tool_use"`
+	OnError     bool `yaml:"on_error" json:"on_error"`
+	OnResult    bool `yaml:"on_result" json:"on_result"`
+	OnStart     bool `yaml:"on_start" json:"on_start"`
+	OnToolUse   bool `yaml:"on_tool_use" json:"on_tool_use"`
+	OnBudget    bool `yaml:"on_budget" json:"on_budget"`
+	OnGuardrail bool `yaml:"on_guardrail" json:"on_guardrail"`
+	OnApproval  bool `yaml:"on_approval" json:"on_approval"`
 }
 
 // IsConfigured returns true if the config has enough information to send messages.
@@ -7,7 +7,8 @@ import (
 	"belaykit"
 )
 
-// HandlerOption configures the EventHandler returned by NewEventHandler.
+// HandlerOption configures the handlers returned by NewEventHandler and
+// NewAsyncEventHandler.
 type HandlerOption func(*handlerConfig)
 
 // ErrorFormatter formats an error event into text + optional blocks.
@@ -21,8 +22,14 @@ type handlerConfig struct {
 	errorFormatter ErrorFormatter
 	resultFormatter ResultFormatter
 	ctx            context.Context
+	bufferSize     int
+	overflow       belaykit.OverflowPolicy
 }
 
+// DefaultHandlerBufferSize is how many notifications an event handler queues
+// before its overflow policy applies.
+const DefaultHandlerBufferSize = 64
+
 // WithHandlerAgentName sets the agent name included in default notification messages.
 func WithHandlerAgentName(name string) HandlerOption {
 	return func(cfg *handlerConfig) { cfg.agentName = name }
@@ -44,6 +51,18 @@ func WithHandlerContext(ctx context.Context) HandlerOption {
 	return func(cfg *handlerConfig) { cfg.ctx = ctx }
 }
 
+// WithHandlerBuffer sets how many notifications may wait for delivery, and
+// what happens to events that arrive while the buffer is full. Defaults to
+// DefaultHandlerBufferSize and belaykit.OverflowDropOldest, so a slow Slack
+// API does not stall the run; the session start and end are never dropped.
+// belaykit.OverflowBlock trades that for never losing a notification.
+func WithHandlerBuffer(size int, policy belaykit.OverflowPolicy) HandlerOption {
+	return func(cfg *handlerConfig) {
+		cfg.bufferSize = size
+		cfg.overflow = policy
+	}
+}
+
 // defaultErrorFormatter formats an error event for Slack.
 func defaultErrorFormatter(agentName string) ErrorFormatter {
 	return func(e belaykit.Event) (string, []Block) {
@@ -70,17 +89,33 @@ func defaultResultFormatter(agentName string) ResultFormatter {
 }
 
 // NewEventHandler returns a belaykit.EventHandler that dispatches Slack
-// notifications based on the notifier's EventConfig. Slack calls are made in
-// goroutines so the handler never blocks the event stream.
+// notifications based on the notifier's EventConfig. It is the Handle method
+// of NewAsyncEventHandler, for callers that do not need to flush: it blocks
+// only if the buffer holds nothing but session starts and ends, and its
+// delivery goroutine exits whenever no notifications are
+// pending, so nothing leaks when the handler is dropped. Notifications still
+// pending when the program exits are lost.
 //
 // Composable with belaykit.NewLogger:
 //
 //	slackH := slack.NewEventHandler(notifier, ...)
 //	logH := belaykit.NewLogger(os.Stderr, ...)
-//	combined := func(e belaykit.Event) { logH(e); slackH(e) }
+//	combined := belaykit.MultiHandler(logH, slackH)
 func NewEventHandler(notifier *Notifier, opts ...HandlerOption) belaykit.EventHandler {
+	return NewAsyncEventHandler(notifier, opts...).Handle
+}
+
+// NewAsyncEventHandler returns a handler that dispatches Slack notifications
+// based on the notifier's EventConfig. Slack calls are made on a separate
+// goroutine, in event order, so the handler does not block the event stream
+// (see WithHandlerBuffer) and replies land in the
+// session's thread. Call Close before exiting to deliver pending
+// notifications.
+func NewAsyncEventHandler(notifier *Notifier, opts ...HandlerOption) *belaykit.AsyncEventHandler {
 	cfg := handlerConfig{
-		ctx: context.Background(),
+		ctx:        context.Background(),
+		bufferSize: DefaultHandlerBufferSize,
+		overflow:   belaykit.OverflowDropOldest,
 	}
 	for _, opt := range opts {
 		opt(&cfg)
@@ -96,7 +131,7 @@ func NewEventHandler(notifier *Notifier, opts ...HandlerOption) belaykit.EventHa
 	events := notifier.cfg.Events
 	sessionStarted := false
 
-	return func(e belaykit.Event) {
+	dispatch := func(e belaykit.Event) {
 		if !notifier.IsEnabled() {
 			return
 		}
@@ -112,19 +147,34 @@ func NewEventHandler(notifier *Notifier, opts ...HandlerOption) belaykit.EventHa
 				if e.SessionID != "" {
 					text += fmt.Sprintf(" (session: %s)", e.SessionID)
 				}
-				go notifier.StartSession(cfg.ctx, text)
+				notifier.StartSession(cfg.ctx, text)
 			}
 
 		case belaykit.EventResultError:
 			if events.OnError {
 				text, blocks := cfg.errorFormatter(e)
-				go notifier.Send(cfg.ctx, text, blocks...)
+				notifier.Send(cfg.ctx, text, blocks...)
 			}
 
 		case belaykit.EventResult:
 			if events.OnResult {
 				text, blocks := cfg.resultFormatter(e)
-				go notifier.EndSession(cfg.ctx, text, blocks...)
+				notifier.EndSession(cfg.ctx, text, blocks...)
+			}
+
+		case belaykit.EventBudget:
+			if events.OnBudget {
+				notifier.Send(cfg.ctx, formatBudget(cfg.agentName, e))
+			}
+
+		case belaykit.EventGuardrail:
+			if events.OnGuardrail {
+				notifier.Send(cfg.ctx, formatGuardrail(cfg.agentName, e))
+			}
+
+		case belaykit.EventApproval:
+			if events.OnApproval {
+				notifier.Send(cfg.ctx, formatApproval(cfg.agentName, e))
 			}
 
 		case belaykit.EventToolUse:
@@ -133,8 +183,48 @@ func NewEventHandler(notifier *Notifier, opts ...HandlerOption) belaykit.EventHa
 				if cfg.agentName != "" {
 					text = fmt.Sprintf("[%s] Tool: %s", cfg.agentName, e.ToolName)
 				}
-				go notifier.Send(cfg.ctx, text)
+				notifier.Send(cfg.ctx, text)
 			}
 		}
 	}
+
+	return belaykit.AsyncHandler(dispatch, cfg.bufferSize, cfg.overflow)
+}
+
+// formatGuardrail describes the tool call a guardrail blocked.
+func formatGuardrail(agentName string, e belaykit.Event) string {
+	prefix := "Guardrail"
+	if agentName != "" {
+		prefix = fmt.Sprintf("[%s] %s", agentName, prefix)
+	}
+	return fmt.Sprintf("%s %s blocked %s: %s", prefix, e.Subtype, e.ToolName, e.ToolInput)
+}
+
+// formatApproval describes a tool approver's decision.
+func formatApproval(agentName string, e belaykit.Event) string {
+	prefix := "Approved"
+	if e.Subtype == belaykit.ApprovalDeny {
+		prefix = "Denied"
+	}
+	if agentName != "" {
+		prefix = fmt.Sprintf("[%s] %s", agentName, prefix)
+	}
+	text := fmt.Sprintf("%s %s", prefix, e.ToolName)
+	if e.Text != "" {
+		text += ": " + e.Text
+	}
+	return text
+}
+
+// formatBudget describes a budget event's spend and remaining headroom.
+func formatBudget(agentName string, e belaykit.Event) string {
+	prefix := "Budget"
+	if e.IsError {
+		prefix = "Budget exceeded"
+	}
+	if agentName != "" {
+		prefix = fmt.Sprintf("[%s] %s", agentName, prefix)
+	}
+	return fmt.Sprintf("%s (%s): $%.4f of $%.4f spent, $%.4f remaining",
+		prefix, e.Subtype, e.BudgetSpentUSD, e.BudgetLimitUSD, e.BudgetLimitUSD-e.BudgetSpentUSD)
 }
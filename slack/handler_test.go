@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -258,3 +259,236 @@ func TestEventHandlerComposable(t *testing.T) {
 		t.Error("log handler should have been called")
 	}
 }
+
+func TestNewEventHandlerBudget(t *testing.T) {
+	var mu sync.Mutex
+	var requests []PostMessageRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req PostMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(PostMessageResponse{OK: true, TS: "1700000000.000001"})
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		Enabled:  true,
+		BotToken: "xoxb-test",
+		Channel:  "C123",
+		Events:   EventConfig{OnBudget: true},
+	}
+	notifier := NewNotifier(cfg,
+		WithAPIBaseURL(srv.URL),
+		WithRetryConfig(RetryConfig{Backoff: []time.Duration{10 * time.Millisecond}}),
+	)
+	handler := NewEventHandler(notifier, WithHandlerAgentName("myagent"))
+
+	handler(belaykit.Event{
+		Type:           belaykit.EventBudget,
+		Subtype:        belaykit.BudgetScopeShared,
+		BudgetSpentUSD: 1.5,
+		BudgetLimitUSD: 1,
+		IsError:        true,
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	want := "[myagent] Budget exceeded (shared): $1.5000 of $1.0000 spent, $-0.5000 remaining"
+	if requests[0].Text != want {
+		t.Errorf("text = %q, want %q", requests[0].Text, want)
+	}
+}
+
+func TestNewEventHandlerGuardrail(t *testing.T) {
+	var mu sync.Mutex
+	var requests []PostMessageRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req PostMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(PostMessageResponse{OK: true, TS: "1700000000.000001"})
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		Enabled:  true,
+		BotToken: "xoxb-test",
+		Channel:  "C123",
+		Events:   EventConfig{OnGuardrail: true},
+	}
+	notifier := NewNotifier(cfg, WithAPIBaseURL(srv.URL))
+	handler := NewAsyncEventHandler(notifier, WithHandlerAgentName("myagent"))
+
+	handler.Handle(belaykit.Event{
+		Type:      belaykit.EventGuardrail,
+		Subtype:   "no-rm",
+		ToolName:  "Bash",
+		ToolInput: json.RawMessage(`{"command":"rm -rf /"}`),
+		IsError:   true,
+	})
+	handler.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	want := `[myagent] Guardrail no-rm blocked Bash: {"command":"rm -rf /"}`
+	if requests[0].Text != want {
+		t.Errorf("text = %q, want %q", requests[0].Text, want)
+	}
+}
+
+func TestNewEventHandlerApproval(t *testing.T) {
+	var mu sync.Mutex
+	var requests []PostMessageRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req PostMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(PostMessageResponse{OK: true, TS: "1700000000.000001"})
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		Enabled:  true,
+		BotToken: "xoxb-test",
+		Channel:  "C123",
+		Events:   EventConfig{OnApproval: true},
+	}
+	notifier := NewNotifier(cfg, WithAPIBaseURL(srv.URL))
+	handler := NewAsyncEventHandler(notifier, WithHandlerAgentName("myagent"))
+
+	handler.Handle(belaykit.Event{Type: belaykit.EventApproval, Subtype: belaykit.ApprovalAllow, ToolName: "Read"})
+	handler.Handle(belaykit.Event{Type: belaykit.EventApproval, Subtype: belaykit.ApprovalDeny, ToolName: "Bash", Text: "no shell"})
+	handler.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"[myagent] Approved Read", "[myagent] Denied Bash: no shell"}
+	if len(requests) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(requests))
+	}
+	for i, w := range want {
+		if requests[i].Text != w {
+			t.Errorf("text %d = %q, want %q", i, requests[i].Text, w)
+		}
+	}
+}
+
+func TestAsyncEventHandlerKeepsOrder(t *testing.T) {
+	var mu sync.Mutex
+	var requests []PostMessageRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req PostMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(PostMessageResponse{OK: true, TS: "1700000000.000001"})
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		Enabled:  true,
+		BotToken: "xoxb-test",
+		Channel:  "C123",
+		Events:   EventConfig{OnStart: true, OnToolUse: true, OnResult: true},
+	}
+	notifier := NewNotifier(cfg, WithAPIBaseURL(srv.URL))
+	handler := NewAsyncEventHandler(notifier)
+
+	// Back to back, with no pauses: replies must still follow the session
+	// start and land in its thread.
+	handler.Handle(belaykit.Event{Type: belaykit.EventSystem, Subtype: "init", SessionID: "sess-1"})
+	handler.Handle(belaykit.Event{Type: belaykit.EventToolUse, ToolName: "Bash"})
+	handler.Handle(belaykit.Event{Type: belaykit.EventResult, NumTurns: 1})
+	handler.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(requests))
+	}
+	if requests[0].ThreadTS != "" {
+		t.Errorf("StartSession should not have thread_ts")
+	}
+	if requests[1].Text != "Tool: Bash" || requests[1].ThreadTS != "1700000000.000001" {
+		t.Errorf("tool notification = %+v, want threaded Tool: Bash", requests[1])
+	}
+	if requests[2].ThreadTS != "1700000000.000001" {
+		t.Errorf("result notification should be threaded, got %+v", requests[2])
+	}
+	if handler.Dropped() != 0 {
+		t.Errorf("Dropped = %d, want 0", handler.Dropped())
+	}
+}
+
+func TestAsyncEventHandlerDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var requests []PostMessageRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		var req PostMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(PostMessageResponse{OK: true, TS: "1700000000.000001"})
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		Enabled:  true,
+		BotToken: "xoxb-test",
+		Channel:  "C123",
+		Events:   EventConfig{OnStart: true, OnToolUse: true, OnResult: true},
+	}
+	handler := NewAsyncEventHandler(NewNotifier(cfg, WithAPIBaseURL(srv.URL)))
+
+	// Slack is stuck, so the buffer fills; Handle must keep returning, and
+	// drop tool notifications rather than the session start and end.
+	done := make(chan struct{})
+	go func() {
+		handler.Handle(belaykit.Event{Type: belaykit.EventSystem, Subtype: "init", SessionID: "sess-1"})
+		for range DefaultHandlerBufferSize + 10 {
+			handler.Handle(belaykit.Event{Type: belaykit.EventToolUse, ToolName: "Bash"})
+		}
+		handler.Handle(belaykit.Event{Type: belaykit.EventResult, NumTurns: 1})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handle blocked while the buffer was full")
+	}
+	if handler.Dropped() == 0 {
+		t.Error("Dropped = 0, want the overflow counted")
+	}
+	close(release)
+	handler.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) == 0 || !strings.Contains(requests[0].Text, "Session started") {
+		t.Fatalf("first notification = %+v, want the session start", requests)
+	}
+	if last := requests[len(requests)-1]; last.ThreadTS != "1700000000.000001" || strings.HasPrefix(last.Text, "Tool:") {
+		t.Errorf("last notification = %+v, want the threaded result", last)
+	}
+}
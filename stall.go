@@ -0,0 +1,89 @@
+package belaykit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStalled indicates a run was stopped because it stopped making
+// progress. Errors returned for such runs match it with errors.Is and carry
+// a *StallError with the details.
+var ErrStalled = errors.New("run stalled")
+
+// StallError reports an idle or tool timeout. ToolName and ToolID are set
+// when a tool call never returned; otherwise the CLI went silent.
+type StallError struct {
+	Timeout  time.Duration
+	ToolName string
+	ToolID   string
+}
+
+func (e *StallError) Error() string {
+	if e.ToolID != "" {
+		return fmt.Sprintf("tool %s (%s) did not return within %s", e.ToolName, e.ToolID, e.Timeout)
+	}
+	return fmt.Sprintf("no events for %s", e.Timeout)
+}
+
+// Is reports whether target is ErrStalled.
+func (e *StallError) Is(target error) bool {
+	return target == ErrStalled
+}
+
+// stallCheckInterval returns how often the watchdog polls for stalls: a
+// tenth of the shortest timeout, but no more often than every 10ms.
+func stallCheckInterval(timeouts ...time.Duration) time.Duration {
+	var shortest time.Duration
+	for _, d := range timeouts {
+		if d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	return max(shortest/10, 10*time.Millisecond)
+}
+
+// watchStalls aborts the run when it exceeds its idle or tool timeout. It
+// runs until End is called or the run is aborted.
+func (t *RunTracker) watchStalls() {
+	ticker := time.NewTicker(stallCheckInterval(t.cfg.IdleTimeout, t.cfg.ToolTimeout))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.checkStalls(); err != nil {
+				t.Abort(err)
+				return
+			}
+		}
+	}
+}
+
+// checkStalls returns a *StallError if the run has gone quiet for longer
+// than its idle timeout or has a tool call older than its tool timeout.
+func (t *RunTracker) checkStalls() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if d := t.cfg.IdleTimeout; d > 0 && now.Sub(t.lastEvent) >= d {
+		return &StallError{Timeout: d}
+	}
+	if d := t.cfg.ToolTimeout; d > 0 {
+		for id, start := range t.toolStart {
+			if now.Sub(start) >= d {
+				return &StallError{
+					Timeout:  d,
+					ToolName: t.result.ToolCalls[t.toolIndex[id]].Name,
+					ToolID:   id,
+				}
+			}
+		}
+	}
+	return nil
+}
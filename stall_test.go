@@ -0,0 +1,77 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunTrackerIdleTimeout(t *testing.T) {
+	cfg := NewRunConfig(WithIdleTimeout(50 * time.Millisecond))
+	ctx, tr := BeginRun(t.Context(), cfg, "", nil)
+	defer tr.End()
+
+	tr.Emit(Event{Type: EventSystem, Subtype: "init"})
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("run was not aborted after idle timeout")
+	}
+	if !tr.Aborted() {
+		t.Error("Aborted should be true")
+	}
+
+	err := tr.InterruptError()
+	var serr *StallError
+	if !errors.As(err, &serr) || !errors.Is(err, ErrStalled) || !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("err = %v, want interrupted StallError", err)
+	}
+	if serr.ToolID != "" || serr.Timeout != 50*time.Millisecond {
+		t.Errorf("StallError = %+v", serr)
+	}
+}
+
+func TestRunTrackerToolTimeout(t *testing.T) {
+	cfg := NewRunConfig(WithToolTimeout(50 * time.Millisecond))
+	ctx, tr := BeginRun(t.Context(), cfg, "", nil)
+	defer tr.End()
+
+	// A tool that returns in time does not trip the timeout.
+	tr.Emit(Event{Type: EventToolUse, ToolID: "t1", ToolName: "Read"})
+	tr.Emit(Event{Type: EventToolResult, ToolID: "t1", Text: "ok"})
+	tr.Emit(Event{Type: EventToolUse, ToolID: "t2", ToolName: "Bash"})
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("run was not aborted after tool timeout")
+	}
+
+	var serr *StallError
+	if !errors.As(tr.InterruptError(), &serr) {
+		t.Fatalf("err = %v, want StallError", tr.InterruptError())
+	}
+	if serr.ToolName != "Bash" || serr.ToolID != "t2" {
+		t.Errorf("StallError = %+v, want stuck Bash call t2", serr)
+	}
+	if got, want := serr.Error(), "tool Bash (t2) did not return within 50ms"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestRunTrackerEndStopsStallWatchdog(t *testing.T) {
+	cfg := NewRunConfig(WithIdleTimeout(20 * time.Millisecond))
+	ctx, tr := BeginRun(context.Background(), cfg, "", nil)
+	tr.End()
+	tr.End()
+
+	time.Sleep(60 * time.Millisecond)
+	if tr.Aborted() {
+		t.Error("ended run should not be aborted by the watchdog")
+	}
+	if !errors.Is(context.Cause(ctx), context.Canceled) {
+		t.Errorf("cause = %v, want context.Canceled", context.Cause(ctx))
+	}
+}
@@ -1,6 +1,9 @@
 package belaykit
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // EventType identifies the type of streaming event.
 type EventType string
@@ -25,6 +28,28 @@ const (
 	// belaykit does not generate this automatically; callers emit it to tell
 	// observability providers that a new phase is starting.
 	EventPhase EventType = "phase"
+	// EventAttempt is emitted by composite agents (such as Failover) before
+	// each attempt, naming the provider that is about to run.
+	EventAttempt EventType = "attempt"
+	// EventBudget reports a run's spend against a cost limit. It is emitted
+	// when the run ends and when a limit is exceeded; Subtype is the budget
+	// scope ("run" or "shared").
+	EventBudget EventType = "budget"
+	// EventGuardrail reports a tool call that broke a guardrail (see
+	// WithGuardrails). It follows the offending tool_use event; Subtype is
+	// the rule's name and the tool fields are copied from the tool call.
+	EventGuardrail EventType = "guardrail"
+	// EventApproval reports a tool approver's decision on a tool call (see
+	// claude.WithToolApprover). Subtype is ApprovalAllow or ApprovalDeny;
+	// ToolInput is the input the tool runs with and Text the reason for a
+	// denial.
+	EventApproval EventType = "approval"
+)
+
+// Approval decisions, reported in the Subtype of EventApproval events.
+const (
+	ApprovalAllow = "allow"
+	ApprovalDeny  = "deny"
 )
 
 // Event represents a parsed streaming event from an agent.
@@ -36,6 +61,7 @@ type Event struct {
 	// System event fields
 	SessionID string
 	Subtype   string // "init", "success", "error"
+	Model     string // Model reported by the CLI on init, if any
 
 	// Tool use fields
 	ToolName  string
@@ -48,8 +74,32 @@ type Event struct {
 	NumTurns int
 	IsError  bool
 
+	// Token usage fields (only set on result events, when reported)
+	InputTokens         int
+	OutputTokens        int
+	CacheReadTokens     int
+	CacheCreationTokens int
+
 	// Phase fields (only set for EventPhase events)
 	PhaseName string
+
+	// Budget fields (only set for EventBudget events)
+	BudgetSpentUSD float64
+	BudgetLimitUSD float64
+
+	// Attempt fields (only set for EventAttempt events)
+	Attempt  int    // 1-based attempt number
+	Provider string // Name of the agent serving the attempt
+
+	// JobID identifies the job that produced the event when runs are
+	// interleaved (see RunAll and WithJobID).
+	JobID string
+
+	// Run fields, stamped on every event a run emits (see BeginRun).
+	RunID       string    // Unique to one Run call; reported in Result.RunID
+	ParentRunID string    // Run that started this one, if any (see WithParentRunID)
+	Seq         int64     // 1-based position of the event within its run
+	Time        time.Time // When the event was emitted
 }
 
 // EventHandler processes streaming events from a Run invocation.
@@ -58,15 +108,35 @@ type EventHandler func(Event)
 // StreamEvent is the raw JSON structure from Claude's stream-json output.
 // Exported for use by agent implementations.
 type StreamEvent struct {
-	Type       string         `json:"type"`
-	Subtype    string         `json:"subtype,omitempty"`
-	SessionID  string         `json:"session_id,omitempty"`
-	Message    *StreamMessage `json:"message,omitempty"`
-	Result     string         `json:"result,omitempty"`
-	CostUSD    float64        `json:"cost_usd,omitempty"`
-	DurationMS int64          `json:"duration_ms,omitempty"`
-	NumTurns   int            `json:"num_turns,omitempty"`
-	IsError    bool           `json:"is_error,omitempty"`
+	Type         string         `json:"type"`
+	Subtype      string         `json:"subtype,omitempty"`
+	SessionID    string         `json:"session_id,omitempty"`
+	Model        string         `json:"model,omitempty"`
+	Message      *StreamMessage `json:"message,omitempty"`
+	Result       string         `json:"result,omitempty"`
+	CostUSD      float64        `json:"cost_usd,omitempty"`
+	TotalCostUSD float64        `json:"total_cost_usd,omitempty"`
+	DurationMS   int64          `json:"duration_ms,omitempty"`
+	NumTurns     int            `json:"num_turns,omitempty"`
+	IsError      bool           `json:"is_error,omitempty"`
+	Usage        *StreamUsage   `json:"usage,omitempty"`
+}
+
+// Cost returns the run cost reported by the event. Newer CLI versions report
+// total_cost_usd; older ones report cost_usd.
+func (e StreamEvent) Cost() float64 {
+	if e.TotalCostUSD != 0 {
+		return e.TotalCostUSD
+	}
+	return e.CostUSD
+}
+
+// StreamUsage holds token usage reported on a result event.
+type StreamUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
 }
 
 // StreamMessage holds the message content from a streaming event.
@@ -0,0 +1,335 @@
+package belaykit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunTracker accumulates a Result from the events of a single run. Agent
+// implementations pass every event they emit through Observe and call
+// Result once the run finishes. Exported so sub-packages (agent
+// implementations) share one definition of how a run is summarized.
+//
+// A tracker created with BeginRun also supervises the run: Emit forwards
+// events to the run's handler and enforces the limits in its RunConfig,
+// cancelling the run's context when one is exceeded.
+//
+// A RunTracker is safe for concurrent use.
+type RunTracker struct {
+	mu        sync.Mutex
+	result    Result
+	start     time.Time
+	toolIndex map[string]int       // toolID -> index into result.ToolCalls
+	toolStart map[string]time.Time // toolID -> time the tool_use was observed
+	now       func() time.Time     // for testing
+
+	// Live cost, from the result event once reported and estimated from
+	// streamed tokens until then.
+	liveCost     float64
+	costReported bool
+	estInput     int
+	estOutput    int
+
+	// Supervision state, set by BeginRun.
+	cfg            RunConfig
+	ctx            context.Context
+	handler        EventHandler
+	stamper        *eventStamper
+	emitMu         sync.Mutex // serializes Emit, and so the handler
+	cancel         context.CancelCauseFunc
+	stop           chan struct{} // closed by End to stop the stall watchdog
+	endOnce        sync.Once
+	lastEvent      time.Time
+	aborted        bool
+	runExceeded    bool
+	budgetExceeded bool
+	violated       bool
+}
+
+// NewRunTracker creates a tracker for a run using the given resolved model.
+// The model is replaced if the CLI reports a more specific one on init.
+func NewRunTracker(model string) *RunTracker {
+	t := &RunTracker{
+		toolIndex: make(map[string]int),
+		toolStart: make(map[string]time.Time),
+		now:       time.Now,
+	}
+	t.result.Model = model
+	t.start = t.now()
+	return t
+}
+
+// BeginRun starts supervising a run. It returns a context derived from ctx
+// that the agent must run the CLI under, and a tracker whose Emit method the
+// agent calls for every event instead of calling handler directly. The
+// tracker cancels the returned context when the run breaks a limit from cfg
+// (cost, idle or tool timeout, guardrails); agents check Aborted once the
+// CLI exits.
+// Every event passed to the handler is stamped with the run's ID, its
+// sequence number and the time it was emitted. Call End when the run is
+// over.
+func BeginRun(ctx context.Context, cfg RunConfig, model string, handler EventHandler) (context.Context, *RunTracker) {
+	t := NewRunTracker(model)
+	t.cfg = cfg
+	t.handler = handler
+	t.stamper = newEventStamper(cfg.ParentRunID, t.now)
+	t.ctx, t.cancel = context.WithCancelCause(ctx)
+	t.stop = make(chan struct{})
+	t.lastEvent = t.start
+	if cfg.IdleTimeout > 0 || cfg.ToolTimeout > 0 {
+		go t.watchStalls()
+	}
+	return t.ctx, t
+}
+
+// Observe folds an event into the run summary.
+func (t *RunTracker) Observe(e Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastEvent = t.now()
+
+	switch e.Type {
+	case EventAssistant, EventToolUse, EventToolResult:
+		in, out := classifyEventTokens(e)
+		t.estInput += in
+		t.estOutput += out
+		if !t.costReported {
+			t.liveCost = t.cfg.Pricing.Cost(t.estInput, t.estOutput)
+		}
+	}
+
+	switch e.Type {
+	case EventSystem:
+		if e.SessionID != "" {
+			t.result.SessionID = e.SessionID
+		}
+		if e.Model != "" {
+			t.result.Model = e.Model
+		}
+	case EventToolUse:
+		t.toolIndex[e.ToolID] = len(t.result.ToolCalls)
+		t.toolStart[e.ToolID] = t.now()
+		t.result.ToolCalls = append(t.result.ToolCalls, ToolCall{
+			ID:    e.ToolID,
+			Name:  e.ToolName,
+			Input: e.ToolInput,
+		})
+	case EventToolResult:
+		i, ok := t.toolIndex[e.ToolID]
+		if !ok {
+			return
+		}
+		call := &t.result.ToolCalls[i]
+		call.Output = e.Text
+		if start, ok := t.toolStart[e.ToolID]; ok {
+			call.DurationMS = t.now().Sub(start).Milliseconds()
+			delete(t.toolStart, e.ToolID)
+		}
+	case EventResult, EventResultError:
+		t.result.Text = e.Text
+		t.result.Subtype = e.Subtype
+		t.result.IsError = e.IsError || e.Type == EventResultError
+		if e.CostUSD != 0 {
+			t.result.CostUSD = e.CostUSD
+			t.liveCost = e.CostUSD
+			t.costReported = true
+		}
+		if e.Duration != 0 {
+			t.result.DurationMS = e.Duration
+		}
+		if e.NumTurns != 0 {
+			t.result.NumTurns = e.NumTurns
+		}
+		if e.InputTokens != 0 || e.OutputTokens != 0 {
+			t.result.InputTokens = e.InputTokens
+			t.result.OutputTokens = e.OutputTokens
+			t.result.CacheReadTokens = e.CacheReadTokens
+			t.result.CacheCreationTokens = e.CacheCreationTokens
+		}
+	}
+}
+
+// Result returns the run summary observed so far. When the CLI did not
+// report a duration, the wall-clock time since the tracker was created is
+// used instead.
+func (t *RunTracker) Result() Result {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := t.result
+	res.ToolCalls = append([]ToolCall(nil), t.result.ToolCalls...)
+	if res.DurationMS == 0 {
+		res.DurationMS = t.now().Sub(t.start).Milliseconds()
+	}
+	if t.stamper != nil {
+		res.RunID = t.stamper.runID
+	}
+	return res
+}
+
+// Emit observes e, forwards it to the run's handler, and emits any events
+// derived from it, such as EventBudget and EventGuardrail. Derived events
+// precede terminal result events so the result is always the last event of
+// a run. Emit is safe to call from several goroutines: calls are delivered
+// one at a time, so the handler is never called concurrently and sees
+// events in Seq order.
+func (t *RunTracker) Emit(e Event) {
+	t.emitMu.Lock()
+	defer t.emitMu.Unlock()
+
+	t.Observe(e)
+	terminal := e.Type == EventResult || e.Type == EventResultError
+	derived := append(t.checkGuardrails(e), t.checkBudget(terminal)...)
+	if terminal {
+		t.forward(derived...)
+		t.forward(e)
+		return
+	}
+	t.forward(e)
+	t.forward(derived...)
+}
+
+func (t *RunTracker) forward(events ...Event) {
+	if t.handler == nil {
+		return
+	}
+	for _, e := range events {
+		t.handler(t.stamper.stamp(e))
+	}
+}
+
+// eventStamper stamps the events of one run with the run's identity and
+// their order within it.
+type eventStamper struct {
+	runID       string
+	parentRunID string
+	now         func() time.Time
+	seq         atomic.Int64
+}
+
+func newEventStamper(parentRunID string, now func() time.Time) *eventStamper {
+	return &eventStamper{runID: uuid.NewString(), parentRunID: parentRunID, now: now}
+}
+
+func (s *eventStamper) stamp(e Event) Event {
+	e.RunID = s.runID
+	e.ParentRunID = s.parentRunID
+	e.Seq = s.seq.Add(1)
+	e.Time = s.now()
+	return e
+}
+
+// Abort stops the run with the given cause. Only the first cause is kept.
+func (t *RunTracker) Abort(cause error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.abortLocked(cause)
+}
+
+func (t *RunTracker) abortLocked(cause error) {
+	if t.aborted || t.cancel == nil {
+		return
+	}
+	t.aborted = true
+	t.cancel(cause)
+}
+
+// Aborted reports whether the tracker stopped the run because a limit was
+// exceeded.
+func (t *RunTracker) Aborted() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.aborted
+}
+
+// InterruptError returns the error for a run that was stopped early. It
+// wraps ErrInterrupted and the cause of the run's context: the caller's
+// cancellation, or the limit that aborted the run.
+func (t *RunTracker) InterruptError() error {
+	return fmt.Errorf("%w: %w", ErrInterrupted, context.Cause(t.ctx))
+}
+
+// End finishes supervision: it charges the run's cost to the shared budget,
+// stops the stall watchdog and releases the run's context. Calling End more
+// than once has no further effect.
+func (t *RunTracker) End() {
+	t.endOnce.Do(func() {
+		t.mu.Lock()
+		cost := t.liveCost
+		t.mu.Unlock()
+
+		if t.cfg.Budget != nil {
+			t.cfg.Budget.commit(t, cost)
+		}
+		if t.stop != nil {
+			close(t.stop)
+		}
+		if t.cancel != nil {
+			t.cancel(nil)
+		}
+	})
+}
+
+// LiveCost returns the run's cost so far: the reported cost once the result
+// event has arrived, otherwise an estimate from streamed tokens priced with
+// the run's ModelPricing.
+func (t *RunTracker) LiveCost() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.liveCost
+}
+
+// checkBudget compares the live cost against the run's limits and returns
+// the EventBudget events to emit. Limits are reported when reached and, for
+// terminal events, always. A limit reached before the terminal event aborts
+// the run; the terminal event's cost is only reported and charged, since
+// the CLI has already finished.
+func (t *RunTracker) checkBudget(terminal bool) []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []Event
+	cost := t.liveCost
+
+	if limit := t.cfg.MaxCostUSD; limit > 0 {
+		exceeded := cost >= limit
+		if terminal || (exceeded && !t.runExceeded) {
+			events = append(events, budgetEvent(BudgetScopeRun, cost, cost, limit))
+		}
+		if exceeded && !t.runExceeded && !terminal {
+			t.runExceeded = true
+			t.abortLocked(&BudgetExceededError{Scope: BudgetScopeRun, LimitUSD: limit, SpentUSD: cost})
+		}
+	}
+
+	if b := t.cfg.Budget; b != nil {
+		spent := b.update(t, cost)
+		exceeded := spent >= b.Limit()
+		if terminal || (exceeded && !t.budgetExceeded) {
+			events = append(events, budgetEvent(BudgetScopeShared, cost, spent, b.Limit()))
+		}
+		if exceeded && !t.budgetExceeded && !terminal {
+			t.budgetExceeded = true
+			t.abortLocked(&BudgetExceededError{Scope: BudgetScopeShared, LimitUSD: b.Limit(), SpentUSD: spent})
+		}
+	}
+
+	return events
+}
+
+func budgetEvent(scope string, runCost, spent, limit float64) Event {
+	return Event{
+		Type:           EventBudget,
+		Subtype:        scope,
+		CostUSD:        runCost,
+		BudgetSpentUSD: spent,
+		BudgetLimitUSD: limit,
+		IsError:        spent >= limit,
+	}
+}
@@ -0,0 +1,155 @@
+package belaykit
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunTrackerResult(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := NewRunTracker("sonnet")
+	tr.now = func() time.Time { return clock }
+
+	tr.Observe(Event{Type: EventSystem, Subtype: "init", SessionID: "sess-1", Model: "claude-sonnet-4-5-20250929"})
+	tr.Observe(Event{Type: EventToolUse, ToolName: "Bash", ToolID: "tool_01", ToolInput: json.RawMessage(`{"command":"ls"}`)})
+	clock = clock.Add(1500 * time.Millisecond)
+	tr.Observe(Event{Type: EventToolResult, ToolID: "tool_01", Text: "file1.go"})
+	tr.Observe(Event{Type: EventToolUse, ToolName: "Read", ToolID: "tool_02"})
+	tr.Observe(Event{
+		Type:                EventResult,
+		Text:                "done",
+		Subtype:             "success",
+		CostUSD:             0.25,
+		Duration:            4200,
+		NumTurns:            3,
+		InputTokens:         1000,
+		OutputTokens:        200,
+		CacheReadTokens:     800,
+		CacheCreationTokens: 50,
+	})
+
+	res := tr.Result()
+	if res.Text != "done" {
+		t.Errorf("Text = %q, want %q", res.Text, "done")
+	}
+	if res.SessionID != "sess-1" {
+		t.Errorf("SessionID = %q, want %q", res.SessionID, "sess-1")
+	}
+	if res.Model != "claude-sonnet-4-5-20250929" {
+		t.Errorf("Model = %q, want reported model", res.Model)
+	}
+	if res.Subtype != "success" || res.IsError {
+		t.Errorf("Subtype = %q IsError = %v, want success/false", res.Subtype, res.IsError)
+	}
+	if res.CostUSD != 0.25 || res.DurationMS != 4200 || res.NumTurns != 3 {
+		t.Errorf("cost/duration/turns = %v/%d/%d", res.CostUSD, res.DurationMS, res.NumTurns)
+	}
+	if res.InputTokens != 1000 || res.OutputTokens != 200 || res.CacheReadTokens != 800 || res.CacheCreationTokens != 50 {
+		t.Errorf("tokens = %d/%d/%d/%d", res.InputTokens, res.OutputTokens, res.CacheReadTokens, res.CacheCreationTokens)
+	}
+	if len(res.ToolCalls) != 2 {
+		t.Fatalf("ToolCalls len = %d, want 2", len(res.ToolCalls))
+	}
+	if tc := res.ToolCalls[0]; tc.Name != "Bash" || tc.Output != "file1.go" || tc.DurationMS != 1500 {
+		t.Errorf("ToolCalls[0] = %+v", tc)
+	}
+	if tc := res.ToolCalls[1]; tc.Name != "Read" || tc.DurationMS != 0 {
+		t.Errorf("ToolCalls[1] = %+v, want unfinished Read call", tc)
+	}
+}
+
+func TestRunTrackerErrorResult(t *testing.T) {
+	tr := NewRunTracker("opus")
+	tr.Observe(Event{Type: EventResultError, Text: "boom", Subtype: "error_max_turns"})
+
+	res := tr.Result()
+	if !res.IsError {
+		t.Error("IsError should be true for result_error events")
+	}
+	if res.Model != "opus" {
+		t.Errorf("Model = %q, want %q", res.Model, "opus")
+	}
+	if res.Subtype != "error_max_turns" {
+		t.Errorf("Subtype = %q, want %q", res.Subtype, "error_max_turns")
+	}
+}
+
+func TestRunTrackerWallClockDuration(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := NewRunTracker("")
+	tr.now = func() time.Time { return clock }
+	tr.start = clock
+
+	clock = clock.Add(2 * time.Second)
+	if got := tr.Result().DurationMS; got != 2000 {
+		t.Errorf("DurationMS = %d, want 2000", got)
+	}
+}
+
+func TestBeginRunStampsEvents(t *testing.T) {
+	var events []Event
+	cfg := NewRunConfig(WithParentRunID("parent-1"), WithMaxCostUSD(1))
+	_, tr := BeginRun(t.Context(), cfg, "", func(e Event) { events = append(events, e) })
+	defer tr.End()
+
+	tr.Emit(Event{Type: EventSystem, Subtype: "init"})
+	tr.Emit(Event{Type: EventAssistant, Text: "hi"})
+	tr.Emit(Event{Type: EventResult, CostUSD: 0.1})
+
+	res := tr.Result()
+	if res.RunID == "" {
+		t.Fatal("Result.RunID is empty")
+	}
+	// The derived budget event is stamped in the order it is delivered.
+	want := []EventType{EventSystem, EventAssistant, EventBudget, EventResult}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, e := range events {
+		if e.Type != want[i] || e.Seq != int64(i+1) {
+			t.Errorf("event %d = %s seq %d, want %s seq %d", i, e.Type, e.Seq, want[i], i+1)
+		}
+		if e.RunID != res.RunID || e.ParentRunID != "parent-1" {
+			t.Errorf("event %d run = %q parent %q", i, e.RunID, e.ParentRunID)
+		}
+		if e.Time.IsZero() || (i > 0 && e.Time.Before(events[i-1].Time)) {
+			t.Errorf("event %d time %v out of order", i, e.Time)
+		}
+	}
+
+	_, other := BeginRun(t.Context(), cfg, "", nil)
+	defer other.End()
+	if other.Result().RunID == res.RunID {
+		t.Error("two runs share a RunID")
+	}
+}
+
+func TestRunTrackerConcurrentEmit(t *testing.T) {
+	// The handler takes no lock: Emit must not call it concurrently.
+	var events []Event
+	_, tr := BeginRun(t.Context(), NewRunConfig(), "", func(e Event) { events = append(events, e) })
+	defer tr.End()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				tr.Emit(Event{Type: EventAssistant})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(events) != 400 {
+		t.Fatalf("got %d events, want 400", len(events))
+	}
+	for i, e := range events {
+		if e.Seq != int64(i+1) {
+			t.Fatalf("event %d has seq %d, want delivery in Seq order", i, e.Seq)
+		}
+	}
+}
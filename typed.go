@@ -0,0 +1,97 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"belaykit/schema"
+)
+
+// DefaultMaxRepairs is how many times RunTyped re-prompts an agent whose
+// reply could not be decoded.
+const DefaultMaxRepairs = 2
+
+// ErrInvalidOutput indicates an agent's reply could not be decoded into the
+// requested type, even after repair attempts.
+var ErrInvalidOutput = errors.New("invalid structured output")
+
+// Validator is implemented by types that check their own decoded values.
+// RunTyped calls Validate after unmarshalling and treats an error like a
+// parse failure.
+type Validator interface {
+	Validate() error
+}
+
+// SchemaEnforcer is implemented by agents that deliver WithOutputSchema to
+// the model themselves, such as the claude and codex clients. Wrappers
+// report whether the agents they wrap do.
+type SchemaEnforcer interface {
+	EnforcesOutputSchema() bool
+}
+
+// enforcesOutputSchema reports whether agent delivers the output schema
+// itself.
+func enforcesOutputSchema(agent Agent) bool {
+	e, ok := agent.(SchemaEnforcer)
+	return ok && e.EnforcesOutputSchema()
+}
+
+// RunTyped runs prompt on agent and decodes the reply into a T. The JSON
+// schema for T (see schema.For) is passed to the agent with
+// WithOutputSchema and, unless the agent is a SchemaEnforcer, appended to
+// the prompt. The reply is checked against the schema and parsed with
+// ExtractValidJSON. If decoding or validation fails, the same
+// session is resumed with the error message and the agent is asked to try
+// again, up to the run's repair limit (see WithMaxRepairs).
+//
+// The returned Result is that of the last turn. Once repairs are exhausted
+// the error wraps ErrInvalidOutput and the last decoding error.
+func RunTyped[T any](ctx context.Context, agent Agent, prompt string, opts ...RunOption) (T, Result, error) {
+	var zero T
+	maxRepairs := NewRunConfig(opts...).MaxRepairs
+	s := schema.For[T]()
+
+	if !enforcesOutputSchema(agent) {
+		prompt += "\n\n" + s.Prompt()
+	}
+	conv := NewConversation(agent, append(slices.Clip(opts), WithOutputSchema(s))...)
+	res, err := conv.Send(ctx, prompt)
+	for attempt := 0; ; attempt++ {
+		if err != nil {
+			return zero, res, err
+		}
+
+		var v T
+		decodeErr := decodeTyped(res.Text, s, &v)
+		if decodeErr == nil {
+			return v, res, nil
+		}
+		if attempt >= maxRepairs || conv.SessionID() == "" {
+			return zero, res, fmt.Errorf("%w after %d attempts: %w", ErrInvalidOutput, attempt+1, decodeErr)
+		}
+
+		res, err = conv.Send(ctx, repairPrompt(decodeErr))
+	}
+}
+
+// decodeTyped extracts JSON from text, checks it against s, and decodes it
+// into dst.
+func decodeTyped(text string, s *schema.Schema, dst any) error {
+	if err := ExtractValidJSON(text, s, dst); err != nil {
+		return err
+	}
+	if v, ok := dst.(Validator); ok {
+		return v.Validate()
+	}
+	if v, ok := reflect.ValueOf(dst).Elem().Interface().(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+func repairPrompt(err error) string {
+	return fmt.Sprintf("Your previous reply could not be used: %v\n\nReply again with only the corrected JSON, matching the schema.", err)
+}
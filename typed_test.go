@@ -0,0 +1,151 @@
+package belaykit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type verdict struct {
+	Label      string   `json:"label"`
+	Confidence float64  `json:"confidence"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+func (v verdict) Validate() error {
+	if v.Label == "" {
+		return errors.New("label is empty")
+	}
+	return nil
+}
+
+func TestRunTyped(t *testing.T) {
+	agent := &promptAgent{scriptedAgent: scriptedAgent{results: []Result{
+		{SessionID: "sess-1", Text: "Here you go:\n```json\n{\"label\": \"spam\", \"confidence\": 0.9}\n```"},
+	}}}
+
+	v, res, err := RunTyped[verdict](t.Context(), agent, "classify this")
+	if err != nil {
+		t.Fatalf("RunTyped error: %v", err)
+	}
+	if v.Label != "spam" || v.Confidence != 0.9 {
+		t.Errorf("value = %+v", v)
+	}
+	if res.SessionID != "sess-1" {
+		t.Errorf("SessionID = %q", res.SessionID)
+	}
+	prompt := agent.prompts[0]
+	if !strings.HasPrefix(prompt, "classify this\n\n") || !strings.Contains(prompt, `"confidence"`) {
+		t.Errorf("prompt = %q, want schema appended", prompt)
+	}
+	if agent.configs[0].OutputSchema == nil {
+		t.Error("OutputSchema not passed to the agent")
+	}
+}
+
+func TestRunTypedRepairs(t *testing.T) {
+	agent := &promptAgent{scriptedAgent: scriptedAgent{results: []Result{
+		{SessionID: "sess-1", Text: "I think it is spam."},
+		{SessionID: "sess-1", Text: `{"label": "ham"}`},
+		{SessionID: "sess-1", Text: `{"label": "", "confidence": 0.5}`},
+		{SessionID: "sess-1", Text: `{"label": "ham", "confidence": 0.5}`},
+	}}}
+
+	v, _, err := RunTyped[verdict](t.Context(), agent, "classify this", WithMaxRepairs(3))
+	if err != nil {
+		t.Fatalf("RunTyped error: %v", err)
+	}
+	if v.Label != "ham" {
+		t.Errorf("Label = %q, want %q", v.Label, "ham")
+	}
+	if len(agent.prompts) != 4 {
+		t.Fatalf("calls = %d, want 4", len(agent.prompts))
+	}
+	if !strings.Contains(agent.prompts[1], ErrNoJSON.Error()) ||
+		!strings.Contains(agent.prompts[2], `$: missing required field "confidence"`) ||
+		!strings.Contains(agent.prompts[3], "label is empty") {
+		t.Errorf("repair prompts = %q", agent.prompts[1:])
+	}
+	for i, cfg := range agent.configs[1:] {
+		if cfg.ResumeSessionID != "sess-1" {
+			t.Errorf("repair %d ResumeSessionID = %q, want %q", i+1, cfg.ResumeSessionID, "sess-1")
+		}
+	}
+}
+
+func TestRunTypedExhausted(t *testing.T) {
+	agent := &promptAgent{scriptedAgent: scriptedAgent{results: []Result{
+		{SessionID: "sess-1", Text: "no"},
+		{SessionID: "sess-1", Text: "still no"},
+	}}}
+
+	_, res, err := RunTyped[verdict](t.Context(), agent, "classify this", WithMaxRepairs(1))
+	if !errors.Is(err, ErrInvalidOutput) || !errors.Is(err, ErrNoJSON) {
+		t.Fatalf("err = %v, want ErrInvalidOutput wrapping ErrNoJSON", err)
+	}
+	if res.Text != "still no" {
+		t.Errorf("Text = %q, want last turn", res.Text)
+	}
+}
+
+func TestRunTypedSlice(t *testing.T) {
+	agent := &promptAgent{scriptedAgent: scriptedAgent{results: []Result{{Text: `[{"label": "a", "confidence": 1}]`}}}}
+
+	v, _, err := RunTyped[[]verdict](t.Context(), agent, "classify all")
+	if err != nil {
+		t.Fatalf("RunTyped error: %v", err)
+	}
+	if len(v) != 1 || v[0].Label != "a" {
+		t.Errorf("value = %+v", v)
+	}
+	if !strings.Contains(agent.prompts[0], "JSON array") {
+		t.Errorf("prompt = %q, want array instructions", agent.prompts[0])
+	}
+}
+
+// enforcingAgent is a promptAgent that delivers the output schema itself.
+type enforcingAgent struct {
+	promptAgent
+}
+
+func (a *enforcingAgent) EnforcesOutputSchema() bool { return true }
+
+func TestRunTypedSchemaEnforcer(t *testing.T) {
+	agent := &enforcingAgent{promptAgent{scriptedAgent: scriptedAgent{results: []Result{
+		{SessionID: "sess-1", Text: `{"label": "spam", "confidence": 0.9}`},
+	}}}}
+
+	for _, a := range []Agent{agent, Retry(agent), Failover(agent, agent)} {
+		if !enforcesOutputSchema(a) {
+			t.Errorf("%T does not report the wrapped agent's SchemaEnforcer", a)
+		}
+	}
+	if enforcesOutputSchema(Failover(agent, &promptAgent{})) {
+		t.Error("Failover with a plain agent reports it enforces the schema")
+	}
+
+	if _, _, err := RunTyped[verdict](t.Context(), agent, "classify this"); err != nil {
+		t.Fatalf("RunTyped error: %v", err)
+	}
+	if prompt := agent.prompts[0]; prompt != "classify this" {
+		t.Errorf("prompt = %q, want the schema left to WithOutputSchema", prompt)
+	}
+	if agent.configs[0].OutputSchema == nil {
+		t.Error("OutputSchema not passed to the agent")
+	}
+}
+
+// promptAgent is a scriptedAgent that also records prompts.
+type promptAgent struct {
+	scriptedAgent
+	prompts []string
+}
+
+func (a *promptAgent) Run(ctx context.Context, prompt string, opts ...RunOption) (Result, error) {
+	a.prompts = append(a.prompts, prompt)
+	if a.errs == nil {
+		a.errs = make([]error, len(a.results))
+	}
+	return a.scriptedAgent.Run(ctx, prompt, opts...)
+}